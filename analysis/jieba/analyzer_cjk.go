@@ -1,6 +1,8 @@
 package jieba
 
 import (
+	"go-search/analysis/zhconv"
+
 	"github.com/blevesearch/bleve/v2/analysis"
 	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
 	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
@@ -8,44 +10,73 @@ import (
 )
 
 const (
-	AnalyzerName  = "jieba"
-	TokenizerName = "jieba_tokenizer"
+	AnalyzerName    = "jieba"
+	T2SAnalyzerName = "jieba_t2s" // 分词前先将繁体字转为简体字
+	TokenizerName   = "jieba_tokenizer"
 )
 
 // 初始化函数：注册分词器和分析器
 func init() {
 	// 注册分词器
+	// 配置项 mode 指定分词模式 (precise、full、search)，默认为精确模式
+	// 配置项 dict_path、hmm_path、user_dict_path、idf_path、stop_words_path 指定词典文件，未指定时使用默认词典
 	registry.RegisterTokenizer(TokenizerName, func(config map[string]interface{}, cache *registry.Cache) (analysis.Tokenizer, error) {
-		return NewJiebaTokenizer(), nil
+		var d Dictionaries
+		for key, path := range map[string]*string{
+			"dict_path":       &d.DictPath,
+			"hmm_path":        &d.HMMPath,
+			"user_dict_path":  &d.UserDictPath,
+			"idf_path":        &d.IDFPath,
+			"stop_words_path": &d.StopWordsPath,
+		} {
+			if v, ok := config[key].(string); ok {
+				*path = v
+			}
+		}
+		mode, _ := config["mode"].(string)
+		return NewJiebaTokenizerWithMode(Mode(mode), d)
 	})
 
 	// 注册分析器
 	registry.RegisterAnalyzer(AnalyzerName, func(config map[string]interface{}, cache *registry.Cache) (analysis.Analyzer, error) {
-		// 获取分词器
-		tokenizer, err := cache.TokenizerNamed(TokenizerName)
+		return newAnalyzer(cache)
+	})
+	registry.RegisterAnalyzer(T2SAnalyzerName, func(config map[string]interface{}, cache *registry.Cache) (analysis.Analyzer, error) {
+		t2sFilter, err := cache.CharFilterNamed(zhconv.T2SName)
 		if err != nil {
 			return nil, err
 		}
+		return newAnalyzer(cache, t2sFilter)
+	})
+}
 
-		// 获取Bleve内置的CJK宽度过滤器（处理全角半角转换）
-		widthFilter, err := cache.TokenFilterNamed(cjk.WidthName)
-		if err != nil {
-			return nil, err
-		}
+// 结巴分词加上全角转半角和小写过滤器组成的分析器
+func newAnalyzer(cache *registry.Cache, charFilters ...analysis.CharFilter) (analysis.Analyzer, error) {
+	// 获取分词器
+	tokenizer, err := cache.TokenizerNamed(TokenizerName)
+	if err != nil {
+		return nil, err
+	}
 
-		// 小写过滤器
-		lowerFilter, err := cache.TokenFilterNamed(lowercase.Name)
-		if err != nil {
-			return nil, err
-		}
+	// 获取Bleve内置的CJK宽度过滤器（处理全角半角转换）
+	widthFilter, err := cache.TokenFilterNamed(cjk.WidthName)
+	if err != nil {
+		return nil, err
+	}
 
-		// 组合分析器
-		return &analysis.DefaultAnalyzer{
-			Tokenizer: tokenizer,
-			TokenFilters: []analysis.TokenFilter{
-				widthFilter,
-				lowerFilter,
-			},
-		}, nil
-	})
+	// 小写过滤器
+	lowerFilter, err := cache.TokenFilterNamed(lowercase.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 组合分析器
+	return &analysis.DefaultAnalyzer{
+		CharFilters: charFilters,
+		Tokenizer:   tokenizer,
+		TokenFilters: []analysis.TokenFilter{
+			widthFilter,
+			lowerFilter,
+		},
+	}, nil
 }
@@ -0,0 +1,135 @@
+package jieba
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/yanyiwu/gojieba"
+)
+
+// 词典文件路径，为空的项使用 gojieba 自带的词典
+type Dictionaries struct {
+	DictPath      string // 主词典
+	HMMPath       string // HMM 模型，用于识别词典中没有的词
+	UserDictPath  string // 用户词典，每行为 "词 [词频] [词性]"
+	IDFPath       string // IDF 词典，用于关键词提取
+	StopWordsPath string // 停用词，用于关键词提取
+}
+
+// 用指定的默认值补全为空的路径
+func (d Dictionaries) withDefaults(defaults Dictionaries) Dictionaries {
+	fill := func(path *string, value string) {
+		if *path == "" {
+			*path = value
+		}
+	}
+	fill(&d.DictPath, defaults.DictPath)
+	fill(&d.HMMPath, defaults.HMMPath)
+	fill(&d.UserDictPath, defaults.UserDictPath)
+	fill(&d.IDFPath, defaults.IDFPath)
+	fill(&d.StopWordsPath, defaults.StopWordsPath)
+	return d
+}
+
+func (d Dictionaries) paths() []string {
+	return []string{d.DictPath, d.HMMPath, d.UserDictPath, d.IDFPath, d.StopWordsPath}
+}
+
+// 检查词典文件是否可读，gojieba 加载失败时会直接终止进程，需要提前检查
+func (d Dictionaries) check() error {
+	for _, path := range d.paths() {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("读取词典失败: %v", err)
+		}
+		f.Close()
+	}
+	return nil
+}
+
+var (
+	// gojieba 自带的词典
+	builtinDictionaries = Dictionaries{
+		DictPath:      gojieba.DICT_PATH,
+		HMMPath:       gojieba.HMM_PATH,
+		UserDictPath:  gojieba.USER_DICT_PATH,
+		IDFPath:       gojieba.IDF_PATH,
+		StopWordsPath: gojieba.STOP_WORDS_PATH,
+	}
+
+	mu                  sync.Mutex
+	defaultDictionaries = builtinDictionaries
+	segmenters          = make(map[Dictionaries]*segmenter)
+)
+
+// SetDictionaries 设置 jieba 分词器默认使用的词典，为空的项使用 gojieba 自带的词典
+// 只影响之后创建的分词器，需在打开索引之前调用
+func SetDictionaries(d Dictionaries) error {
+	d = d.withDefaults(builtinDictionaries)
+	if err := d.check(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	defaultDictionaries = d
+	return nil
+}
+
+// 分词实例，使用相同词典的分词器共用一个实例
+type segmenter struct {
+	mu    sync.RWMutex
+	jieba *gojieba.Jieba
+}
+
+// 获取使用指定词典的分词实例，为空的项使用默认词典
+func getSegmenter(d Dictionaries) (*segmenter, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	d = d.withDefaults(defaultDictionaries)
+	if s, ok := segmenters[d]; ok {
+		return s, nil
+	}
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	s := &segmenter{jieba: gojieba.NewJieba(d.paths()...)}
+	segmenters[d] = s
+	return s, nil
+}
+
+// 持有读锁调用 fn，重新加载词典时不会释放正在使用的实例
+func (s *segmenter) do(fn func(jieba *gojieba.Jieba)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.jieba)
+}
+
+// ReloadUserDict 重新读取所有分词实例的用户词典，返回重新加载的实例数量
+// 词典不可读的实例继续使用原来的词典，并返回错误
+// 已建立索引的文档不会重新分词，需要重建索引后新的词典才对这些文档生效
+func ReloadUserDict() (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	reloaded := 0
+	var errs []error
+	for d, s := range segmenters {
+		if err := d.check(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// 先加载新实例再替换，加载期间分词不受影响
+		jieba := gojieba.NewJieba(d.paths()...)
+		s.mu.Lock()
+		old := s.jieba
+		s.jieba = jieba
+		s.mu.Unlock()
+		old.Free()
+		reloaded++
+	}
+	return reloaded, errors.Join(errs...)
+}
@@ -0,0 +1,50 @@
+package jieba
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func terms(t *JiebaTokenizer, input string) []string {
+	var result []string
+	for _, token := range t.Tokenize([]byte(input)) {
+		result = append(result, string(token.Term))
+	}
+	return result
+}
+
+func TestReloadUserDict(t *testing.T) {
+	userDict := filepath.Join(t.TempDir(), "user.dict.utf8")
+	if err := os.WriteFile(userDict, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenizer, err := NewJiebaTokenizerWithMode(ModePrecise, Dictionaries{UserDictPath: userDict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := terms(tokenizer, "星光色"); len(got) < 2 {
+		t.Fatalf("terms before reload = %v, want split", got)
+	}
+
+	if err := os.WriteFile(userDict, []byte("星光色 100 n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReloadUserDict(); err != nil {
+		t.Fatal(err)
+	}
+	if got := terms(tokenizer, "星光色"); len(got) != 1 || got[0] != "星光色" {
+		t.Errorf("terms after reload = %v, want [星光色]", got)
+	}
+}
+
+func TestDictionariesInvalid(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.utf8")
+	if _, err := NewJiebaTokenizerWithMode(ModePrecise, Dictionaries{UserDictPath: missing}); err == nil {
+		t.Error("missing user dictionary accepted")
+	}
+	if err := SetDictionaries(Dictionaries{DictPath: missing}); err == nil {
+		t.Error("missing dictionary accepted")
+	}
+}
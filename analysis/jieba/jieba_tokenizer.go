@@ -1,51 +1,143 @@
 package jieba
 
 import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/blevesearch/bleve/v2/analysis"
 	"github.com/yanyiwu/gojieba"
 )
 
+// Mode 分词模式
+type Mode string
+
+const (
+	ModePrecise Mode = "precise" // 精确模式，切分出最合理的词
+	ModeFull    Mode = "full"    // 全模式，切分出所有可能的词，召回率高但歧义多
+	ModeSearch  Mode = "search"  // 搜索引擎模式，在精确模式的基础上再切分长词，切分出的短词与长词位于相同位置
+)
+
+// ParseMode 解析分词模式，为空时使用精确模式
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case "":
+		return ModePrecise, nil
+	case ModePrecise, ModeFull, ModeSearch:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("不支持的分词模式: %q", s)
+	}
+}
+
 type JiebaTokenizer struct {
-	jieba *gojieba.Jieba
+	segmenter *segmenter
+	mode      Mode
 }
 
 // 确保JiebaTokenizer实现bleve的Tokenizer接口
 var _ analysis.Tokenizer = &JiebaTokenizer{}
 
+// NewJiebaTokenizer 使用默认词典和精确模式创建分词器，默认词典不可读时 panic
 func NewJiebaTokenizer() *JiebaTokenizer {
-	// 初始化Jieba（使用默认词典）
-	return &JiebaTokenizer{
-		jieba: gojieba.NewJieba(),
+	t, err := NewJiebaTokenizerWithMode(ModePrecise, Dictionaries{})
+	if err != nil {
+		panic(err)
 	}
+	return t
+}
+
+// NewJiebaTokenizerWithMode 使用指定的分词模式和词典创建分词器，词典中为空的项使用默认词典
+func NewJiebaTokenizerWithMode(mode Mode, d Dictionaries) (*JiebaTokenizer, error) {
+	mode, err := ParseMode(string(mode))
+	if err != nil {
+		return nil, err
+	}
+	s, err := getSegmenter(d)
+	if err != nil {
+		return nil, err
+	}
+	return &JiebaTokenizer{segmenter: s, mode: mode}, nil
 }
 
 // Tokenize 实现分词逻辑
+// 词元位置为从1开始的词序号，全模式中起始位置相同的词、搜索引擎模式中长词及其切分出的短词位于相同位置，
+// Start、End 为词在输入中的字节偏移；空白字符不作为词元，也不占用位置，便于短语查询
 func (t *JiebaTokenizer) Tokenize(input []byte) analysis.TokenStream {
-	// 使用Jieba精确模式分词
-	words := t.jieba.Cut(string(input), true)
-	tokens := make(analysis.TokenStream, 0, len(words))
-	pos := 0
+	text := string(input)
+	var words []gojieba.Word
+	t.segmenter.do(func(jieba *gojieba.Jieba) {
+		switch t.mode {
+		case ModeFull:
+			words = fullWords(jieba, text)
+		case ModeSearch:
+			words = jieba.Tokenize(text, gojieba.SearchMode, true)
+		default:
+			words = jieba.Tokenize(text, gojieba.DefaultMode, true)
+		}
+	})
 
-	for _, word := range words {
-		term := []byte(word)
-		start := pos
-		end := pos + len(word)
+	// 位置分组的依据，分组相同的相邻词位于相同位置
+	groups := make([]int, len(words))
+	for i, word := range words {
+		groups[i] = word.Start
+	}
+	if t.mode == ModeSearch {
+		groupSearchWords(words, groups)
+	}
+
+	tokens := make(analysis.TokenStream, 0, len(words))
+	position, lastGroup := 0, -1
+	for i, word := range words {
+		if strings.TrimSpace(word.Str) == "" {
+			continue
+		}
+		if groups[i] != lastGroup {
+			position++
+			lastGroup = groups[i]
+		}
 		tokens = append(tokens, &analysis.Token{
-			Term:     term,
-			Start:    start,
-			End:      end,
-			Position: pos + 1,
+			Term:     []byte(word.Str),
+			Start:    word.Start,
+			End:      word.End,
+			Position: position,
 			Type:     analysis.Ideographic,
 		})
-		pos = end
 	}
 	return tokens
 }
 
-// Close 释放Jieba资源
-func (t *JiebaTokenizer) Close() error {
-	if t.jieba != nil {
-		t.jieba.Free()
+// 全模式分词，CutAll 按起始位置依次返回词典中所有的词，从上一个词的起始位置向后查找每个词的偏移
+func fullWords(jieba *gojieba.Jieba, text string) []gojieba.Word {
+	pieces := jieba.CutAll(text)
+	words := make([]gojieba.Word, 0, len(pieces))
+	pos := 0
+	for _, piece := range pieces {
+		for pos < len(text) && !strings.HasPrefix(text[pos:], piece) {
+			_, size := utf8.DecodeRuneInString(text[pos:])
+			pos += size
+		}
+		if pos == len(text) {
+			break
+		}
+		words = append(words, gojieba.Word{Str: piece, Start: pos, End: pos + len(piece)})
 	}
+	return words
+}
+
+// 搜索引擎模式中长词排在切分出的短词之后，将短词归入所属长词的分组
+func groupSearchWords(words []gojieba.Word, groups []int) {
+	parent := -1
+	for i := len(words) - 1; i >= 0; i-- {
+		if parent >= 0 && words[i].Start >= words[parent].Start && words[i].End <= words[parent].End {
+			groups[i] = words[parent].Start
+		} else {
+			parent = i
+		}
+	}
+}
+
+// Close 分词实例由使用相同词典的分词器共用，不在这里释放
+func (t *JiebaTokenizer) Close() error {
 	return nil
 }
@@ -0,0 +1,73 @@
+package jieba
+
+import (
+	"testing"
+)
+
+func TestJiebaTokenizerModes(t *testing.T) {
+	input := []byte("南京市长江大桥")
+
+	tests := []struct {
+		mode Mode
+		want []string
+	}{
+		{ModePrecise, []string{"南京市", "长江大桥"}},
+		{ModeFull, []string{"南京", "南京市", "京市", "市长", "长江", "长江大桥", "大桥"}},
+		{ModeSearch, []string{"南京", "京市", "南京市", "长江", "大桥", "长江大桥"}},
+	}
+	for _, tt := range tests {
+		tokenizer, err := NewJiebaTokenizerWithMode(tt.mode, Dictionaries{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens := tokenizer.Tokenize(input)
+		if len(tokens) != len(tt.want) {
+			t.Errorf("%s: tokens = %v", tt.mode, tokens)
+			continue
+		}
+		for i, token := range tokens {
+			if string(token.Term) != tt.want[i] || string(input[token.Start:token.End]) != tt.want[i] {
+				t.Errorf("%s: token %d = %s (%d-%d), want %s", tt.mode, i, token.Term, token.Start, token.End, tt.want[i])
+			}
+		}
+	}
+
+	// 搜索引擎模式中切分出的短词与长词位于相同位置
+	tokenizer, err := NewJiebaTokenizerWithMode(ModeSearch, Dictionaries{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := tokenizer.Tokenize(input)
+	for i, token := range tokens {
+		want := 1
+		if i >= 3 {
+			want = 2
+		}
+		if token.Position != want {
+			t.Errorf("%s position = %d, want %d", token.Term, token.Position, want)
+		}
+	}
+
+	if _, err := NewJiebaTokenizerWithMode("hmm", Dictionaries{}); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
+
+func TestJiebaTokenizerPositions(t *testing.T) {
+	input := []byte("小米 NFC手机，星光色")
+	tokens := NewJiebaTokenizer().Tokenize(input)
+
+	// 位置为连续的词序号，空白不作为词元
+	want := []string{"小米", "NFC", "手机", "，", "星光", "色"}
+	if len(tokens) != len(want) {
+		t.Fatalf("tokens = %v", tokens)
+	}
+	for i, token := range tokens {
+		if string(token.Term) != want[i] || token.Position != i+1 {
+			t.Errorf("token %d = %s@%d, want %s@%d", i, token.Term, token.Position, want[i], i+1)
+		}
+		if string(input[token.Start:token.End]) != want[i] {
+			t.Errorf("token %d offsets = %d-%d", i, token.Start, token.End)
+		}
+	}
+}
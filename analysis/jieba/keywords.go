@@ -0,0 +1,29 @@
+package jieba
+
+import (
+	"github.com/yanyiwu/gojieba"
+)
+
+// Keyword 关键词及其 TF-IDF 权重
+type Keyword struct {
+	Word   string
+	Weight float64
+}
+
+// ExtractKeywords 使用默认词典按 TF-IDF 提取权重最高的 topK 个关键词，按权重从高到低排列
+// 词频按默认词典的用户词典分词统计，逆文档频率和停用词来自 IDF 词典和停用词表
+func ExtractKeywords(text string, topK int) ([]Keyword, error) {
+	s, err := getSegmenter(Dictionaries{})
+	if err != nil {
+		return nil, err
+	}
+	var weights []gojieba.WordWeight
+	s.do(func(jieba *gojieba.Jieba) {
+		weights = jieba.ExtractWithWeight(text, topK)
+	})
+	keywords := make([]Keyword, 0, len(weights))
+	for _, w := range weights {
+		keywords = append(keywords, Keyword{Word: w.Word, Weight: w.Weight})
+	}
+	return keywords, nil
+}
@@ -0,0 +1,99 @@
+package jieba
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/yanyiwu/gojieba"
+)
+
+const POSFilterName = "jieba_pos"
+
+// 初始化函数：注册词性过滤器，配置项 keep、drop 为保留或去除的词性列表，只能指定其中一个
+func init() {
+	registry.RegisterTokenFilter(POSFilterName, func(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+		keep, err := configStrings(config, "keep")
+		if err != nil {
+			return nil, err
+		}
+		drop, err := configStrings(config, "drop")
+		if err != nil {
+			return nil, err
+		}
+		return NewPOSFilter(keep, drop)
+	})
+}
+
+func configStrings(config map[string]interface{}, key string) ([]string, error) {
+	list, _ := config[key].([]interface{})
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s 必须是字符串列表: %v", key, item)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// POSFilter 按 jieba 标注的词性保留或去除词元
+// 词性按前缀匹配，如 n 匹配 n、nr、ns、nz 等名词，v 匹配 v、vn 等动词，
+// eng 为英文，m 为数词，x 为标点符号及词典中没有的词
+type POSFilter struct {
+	segmenter *segmenter
+	prefixes  []string
+	keep      bool // true 时只保留匹配的词元，否则去除匹配的词元
+}
+
+// 确保POSFilter实现bleve的TokenFilter接口
+var _ analysis.TokenFilter = &POSFilter{}
+
+// NewPOSFilter 使用默认词典标注词性，keep 和 drop 只能指定其中一个
+func NewPOSFilter(keep, drop []string) (*POSFilter, error) {
+	if (len(keep) == 0) == (len(drop) == 0) {
+		return nil, fmt.Errorf("必须指定 keep 或 drop 其中一个")
+	}
+	s, err := getSegmenter(Dictionaries{})
+	if err != nil {
+		return nil, err
+	}
+	if len(keep) > 0 {
+		return &POSFilter{segmenter: s, prefixes: keep, keep: true}, nil
+	}
+	return &POSFilter{segmenter: s, prefixes: drop}, nil
+}
+
+// Filter 逐个标注词元的词性，被去除的词元不改变其他词元的位置
+func (f *POSFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	output := make(analysis.TokenStream, 0, len(input))
+	f.segmenter.do(func(jieba *gojieba.Jieba) {
+		for _, token := range input {
+			if f.match(tag(jieba, string(token.Term))) == f.keep {
+				output = append(output, token)
+			}
+		}
+	})
+	return output
+}
+
+func (f *POSFilter) match(pos string) bool {
+	for _, prefix := range f.prefixes {
+		if strings.HasPrefix(pos, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// 词元的词性，词元被再次切分时使用最后一个词的词性，中文复合词的词性通常由最后一个词决定
+func tag(jieba *gojieba.Jieba, term string) string {
+	tagged := jieba.Tag(term)
+	if len(tagged) == 0 {
+		return ""
+	}
+	last := tagged[len(tagged)-1]
+	return last[strings.LastIndex(last, "/")+1:]
+}
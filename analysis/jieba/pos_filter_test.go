@@ -0,0 +1,40 @@
+package jieba
+
+import (
+	"testing"
+)
+
+func TestPOSFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		keep, drop []string
+		want       []string
+		positions  []int
+	}{
+		{"keep", []string{"n", "v", "eng"}, nil, []string{"小米", "NFC", "手机"}, []int{1, 3, 4}},
+		{"drop", nil, []string{"u", "x"}, []string{"小米", "NFC", "手机", "非常"}, []int{1, 3, 4, 6}},
+	}
+	for _, tt := range tests {
+		filter, err := NewPOSFilter(tt.keep, tt.drop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens := filter.Filter(NewJiebaTokenizer().Tokenize([]byte("小米的NFC手机，非常好用")))
+		if len(tokens) != len(tt.want) {
+			t.Errorf("%s: tokens = %v", tt.name, tokens)
+			continue
+		}
+		for i, token := range tokens {
+			if string(token.Term) != tt.want[i] || token.Position != tt.positions[i] {
+				t.Errorf("%s: token %d = %s@%d, want %s@%d", tt.name, i, token.Term, token.Position, tt.want[i], tt.positions[i])
+			}
+		}
+	}
+
+	if _, err := NewPOSFilter(nil, nil); err == nil {
+		t.Error("filter without keep or drop accepted")
+	}
+	if _, err := NewPOSFilter([]string{"n"}, []string{"x"}); err == nil {
+		t.Error("filter with both keep and drop accepted")
+	}
+}
@@ -0,0 +1,89 @@
+package ngram
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+const TokenizerName = "ngram"
+
+// 默认的 n-gram 长度
+const (
+	DefaultMinGram = 1
+	DefaultMaxGram = 2
+)
+
+// 初始化函数：注册分词器，配置项 min、max 为 n-gram 的长度范围
+func init() {
+	registry.RegisterTokenizer(TokenizerName, func(config map[string]interface{}, cache *registry.Cache) (analysis.Tokenizer, error) {
+		minGram, maxGram := DefaultMinGram, DefaultMaxGram
+		if v, ok := config["min"].(float64); ok {
+			minGram = int(v)
+		}
+		if v, ok := config["max"].(float64); ok {
+			maxGram = int(v)
+		}
+		if minGram < 1 || maxGram < minGram {
+			return nil, fmt.Errorf("n-gram 长度范围不合法: min=%d, max=%d", minGram, maxGram)
+		}
+		return NewNgramTokenizer(minGram, maxGram), nil
+	})
+}
+
+type NgramTokenizer struct {
+	minGram int
+	maxGram int
+}
+
+// 确保NgramTokenizer实现bleve的Tokenizer接口
+var _ analysis.Tokenizer = &NgramTokenizer{}
+
+func NewNgramTokenizer(minGram, maxGram int) *NgramTokenizer {
+	return &NgramTokenizer{minGram: minGram, maxGram: maxGram}
+}
+
+// Tokenize 按空白字符切分后，为每个词生成长度在 minGram 到 maxGram 之间的字符 n-gram
+func (t *NgramTokenizer) Tokenize(input []byte) analysis.TokenStream {
+	var tokens analysis.TokenStream
+	position := 0
+
+	// 当前词中每个字符的起始字节偏移
+	var offsets []int
+	flush := func(end int) {
+		offsets = append(offsets, end)
+		chars := len(offsets) - 1
+		for i := 0; i < chars; i++ {
+			for n := t.minGram; n <= t.maxGram && i+n <= chars; n++ {
+				position++
+				tokens = append(tokens, &analysis.Token{
+					Term:     input[offsets[i]:offsets[i+n]],
+					Start:    offsets[i],
+					End:      offsets[i+n],
+					Position: position,
+					Type:     analysis.AlphaNumeric,
+				})
+			}
+		}
+		offsets = offsets[:0]
+	}
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRune(input[i:])
+		if unicode.IsSpace(r) {
+			if len(offsets) > 0 {
+				flush(i)
+			}
+		} else {
+			offsets = append(offsets, i)
+		}
+		i += size
+	}
+	if len(offsets) > 0 {
+		flush(len(input))
+	}
+	return tokens
+}
@@ -0,0 +1,22 @@
+package ngram
+
+import (
+	"testing"
+)
+
+func TestNgramTokenizer(t *testing.T) {
+	tokens := NewNgramTokenizer(2, 3).Tokenize([]byte("小米手机 ab"))
+
+	want := []string{"小米", "小米手", "米手", "米手机", "手机", "ab"}
+	if len(tokens) != len(want) {
+		t.Fatalf("tokens = %v", tokens)
+	}
+	for i, token := range tokens {
+		if string(token.Term) != want[i] || token.Position != i+1 {
+			t.Errorf("token %d = %s@%d, want %s@%d", i, token.Term, token.Position, want[i], i+1)
+		}
+	}
+	if last := tokens[len(tokens)-1]; last.Start != 13 || last.End != 15 {
+		t.Errorf("offsets = %d-%d, want 13-15", last.Start, last.End)
+	}
+}
@@ -0,0 +1,131 @@
+package pinyin
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 内置的拼音词典，覆盖 GB2312 的全部汉字、常用多音字的其他读音及少量多音字词语，不依赖外部文件
+//
+//go:embed pinyin.dict
+var dictionaryData string
+
+type dictionary struct {
+	chars      map[rune]string     // 汉字 -> 最常用的拼音
+	alternates map[rune][]string   // 多音字 -> 其他读音
+	words      map[string][]string // 多音字词语 -> 每个字的拼音
+	maxWords   int                 // 最长词语的字数
+}
+
+// 词典在第一次使用时解析，内置词典不合法时 panic
+var loadDictionary = sync.OnceValue(func() *dictionary {
+	d, err := parseDictionary(dictionaryData)
+	if err != nil {
+		panic(err)
+	}
+	return d
+})
+
+// 解析词典，# 开头的行为注释；拼音开头的行为拼音及读这个音的汉字，+ 加拼音开头的行为多音字的其他读音，
+// 汉字开头的行为词语及其中每个字的拼音
+func parseDictionary(data string) (*dictionary, error) {
+	d := &dictionary{chars: make(map[rune]string), alternates: make(map[rune][]string), words: make(map[string][]string)}
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("拼音词典第 %d 行格式错误: %q", i+1, line)
+		}
+
+		if syllable, ok := strings.CutPrefix(fields[0], "+"); ok {
+			for _, char := range strings.Join(fields[1:], "") {
+				if slices.Contains(d.alternates[char], syllable) {
+					return nil, fmt.Errorf("拼音词典第 %d 行的读音 %s %c 重复", i+1, syllable, char)
+				}
+				d.alternates[char] = append(d.alternates[char], syllable)
+			}
+			continue
+		}
+
+		first, _ := utf8.DecodeRuneInString(fields[0])
+		if first < utf8.RuneSelf {
+			for _, char := range strings.Join(fields[1:], "") {
+				if _, exists := d.chars[char]; exists {
+					return nil, fmt.Errorf("拼音词典第 %d 行的汉字 %c 重复", i+1, char)
+				}
+				d.chars[char] = fields[0]
+			}
+			continue
+		}
+
+		count := utf8.RuneCountInString(fields[0])
+		if count != len(fields)-1 {
+			return nil, fmt.Errorf("拼音词典第 %d 行的拼音数量与字数不一致: %q", i+1, line)
+		}
+		d.words[fields[0]] = fields[1:]
+		d.maxWords = max(d.maxWords, count)
+	}
+
+	// 其他读音只能用于已收录的汉字，且不能与最常用的读音相同
+	for char, syllables := range d.alternates {
+		primary, ok := d.chars[char]
+		if !ok {
+			return nil, fmt.Errorf("拼音词典中多音字 %c 没有最常用的读音", char)
+		}
+		if slices.Contains(syllables, primary) {
+			return nil, fmt.Errorf("拼音词典中多音字 %c 的其他读音与最常用的读音 %s 重复", char, primary)
+		}
+	}
+	return d, nil
+}
+
+// Syllables 文本中每个字符的拼音，没有拼音的字符(如字母、数字、标点)对应空字符串
+// 多音字优先按词典中最长的词语确定读音，否则使用最常用的读音
+func Syllables(text string) []string {
+	readings := Readings(text)
+	syllables := make([]string, len(readings))
+	for i, reading := range readings {
+		if len(reading) > 0 {
+			syllables[i] = reading[0]
+		}
+	}
+	return syllables
+}
+
+// Readings 文本中每个字符所有可能的拼音，第一个为 Syllables 使用的读音，没有拼音的字符对应空列表
+// 按词语确定读音的多音字只有一个读音，其他多音字在最常用的读音之后加入词典中的其他读音
+func Readings(text string) [][]string {
+	d := loadDictionary()
+	runes := []rune(text)
+	readings := make([][]string, len(runes))
+	for i := 0; i < len(runes); {
+		if word := d.matchWord(runes[i:]); word != nil {
+			for j, syllable := range word {
+				readings[i+j] = []string{syllable}
+			}
+			i += len(word)
+			continue
+		}
+		if syllable, ok := d.chars[runes[i]]; ok {
+			readings[i] = append([]string{syllable}, d.alternates[runes[i]]...)
+		}
+		i++
+	}
+	return readings
+}
+
+// 从开头按最长的词语匹配，返回匹配词语中每个字的拼音，没有匹配时返回nil
+func (d *dictionary) matchWord(runes []rune) []string {
+	for n := min(d.maxWords, len(runes)); n >= 2; n-- {
+		if word, ok := d.words[string(runes[:n])]; ok {
+			return word
+		}
+	}
+	return nil
+}
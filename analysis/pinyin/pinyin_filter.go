@@ -0,0 +1,153 @@
+package pinyin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+const Name = "pinyin"
+
+// 每个词元最多输出的全拼及首字母数量，避免多个多音字组合出过多的读音
+const maxReadings = 8
+
+// 拼音过滤器输出的词元
+const (
+	OutputOriginal = "original" // 原词元
+	OutputFull     = "full"     // 全拼，如 苹果 -> pingguo
+	OutputInitials = "initials" // 拼音首字母，如 苹果 -> pg
+)
+
+// 初始化函数：注册词元过滤器，配置项 outputs 为输出的词元，未指定时全部输出
+func init() {
+	registry.RegisterTokenFilter(Name, func(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+		list, _ := config["outputs"].([]interface{})
+		outputs := make([]string, 0, len(list))
+		for _, item := range list {
+			output, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("outputs 必须是字符串列表: %v", item)
+			}
+			outputs = append(outputs, output)
+		}
+		return NewPinyinFilter(outputs...)
+	})
+}
+
+// PinyinFilter 在每个含有汉字的词元位置上加入其全拼和拼音首字母，
+// 便于用拼音或首字母搜索中文，拼音使用内置词典，不带声调，ü 写作 v
+// 常用多音字的每个读音都会输出，如 都市 -> dushi、doushi、ds
+type PinyinFilter struct {
+	original bool
+	full     bool
+	initials bool
+}
+
+// 确保PinyinFilter实现bleve的TokenFilter接口
+var _ analysis.TokenFilter = &PinyinFilter{}
+
+// NewPinyinFilter 指定输出的词元，未指定时输出原词元、全拼和首字母；至少需要输出全拼或首字母
+func NewPinyinFilter(outputs ...string) (*PinyinFilter, error) {
+	if len(outputs) == 0 {
+		return &PinyinFilter{original: true, full: true, initials: true}, nil
+	}
+	f := &PinyinFilter{}
+	for _, output := range outputs {
+		switch output {
+		case OutputOriginal:
+			f.original = true
+		case OutputFull:
+			f.full = true
+		case OutputInitials:
+			f.initials = true
+		default:
+			return nil, fmt.Errorf("不支持的拼音输出: %q", output)
+		}
+	}
+	if !f.full && !f.initials {
+		return nil, fmt.Errorf("拼音输出必须包含 %s 或 %s", OutputFull, OutputInitials)
+	}
+	return f, nil
+}
+
+// Filter 拼音与原词元的位置和偏移相同；不含汉字的词元原样保留，
+// 词元中的字母、数字等字符原样出现在拼音中，如 NFC手机 -> nfcshouji、nfcsj
+func (f *PinyinFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	output := make(analysis.TokenStream, 0, len(input)*2)
+	for _, token := range input {
+		full, initials, ok := convert(string(token.Term))
+		if !ok {
+			output = append(output, token)
+			continue
+		}
+
+		if f.original {
+			output = append(output, token)
+		}
+		var terms []string
+		if f.full {
+			terms = append(terms, full...)
+		}
+		if f.initials {
+			for _, term := range initials {
+				if !slices.Contains(terms, term) {
+					terms = append(terms, term)
+				}
+			}
+		}
+		for _, term := range terms {
+			output = append(output, &analysis.Token{
+				Term:     []byte(term),
+				Start:    token.Start,
+				End:      token.End,
+				Position: token.Position,
+				Type:     analysis.AlphaNumeric,
+			})
+		}
+	}
+	return output
+}
+
+// 词元所有读音组合的全拼和首字母，第一个为使用最常用读音的组合，词元中没有汉字时 ok 为 false
+func convert(term string) (full, initials []string, ok bool) {
+	readings := Readings(term)
+	full, initials = []string{""}, []string{""}
+	for i, r := range []rune(term) {
+		if len(readings[i]) == 0 {
+			lower := strings.ToLower(string(r))
+			full = combine(full, []string{lower})
+			initials = combine(initials, []string{lower})
+			continue
+		}
+		var firsts []string
+		for _, syllable := range readings[i] {
+			if first := syllable[:1]; !slices.Contains(firsts, first) {
+				firsts = append(firsts, first)
+			}
+		}
+		full = combine(full, readings[i])
+		initials = combine(initials, firsts)
+		ok = true
+	}
+	if !ok {
+		return nil, nil, false
+	}
+	return full, initials, true
+}
+
+// 在每个前缀之后接上每个后缀，结果最多 maxReadings 个
+func combine(prefixes, suffixes []string) []string {
+	result := make([]string, 0, min(len(prefixes)*len(suffixes), maxReadings))
+	for _, prefix := range prefixes {
+		for _, suffix := range suffixes {
+			if len(result) == maxReadings {
+				return result
+			}
+			result = append(result, prefix+suffix)
+		}
+	}
+	return result
+}
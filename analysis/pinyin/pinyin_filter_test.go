@@ -0,0 +1,106 @@
+package pinyin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+)
+
+func TestDictionary(t *testing.T) {
+	d, err := parseDictionary(dictionaryData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.chars) != 6763 {
+		t.Errorf("dictionary has %d chars, want 6763", len(d.chars))
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"苹果手机", []string{"ping", "guo", "shou", "ji"}},
+		{"绿色", []string{"lv", "se"}},
+		{"招商银行", []string{"zhao", "shang", "yin", "hang"}},
+		{"行李", []string{"xing", "li"}},
+		{"iPhone 15", []string{"", "", "", "", "", "", "", "", ""}},
+	}
+	for _, tt := range tests {
+		if got := Syllables(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Syllables(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// 多音字输出所有读音，按词语确定读音时只有一个
+	readings := Readings("都市银行")
+	want := [][]string{{"du", "dou"}, {"shi"}, {"yin"}, {"hang"}}
+	if !slices.EqualFunc(readings, want, slices.Equal[[]string]) {
+		t.Errorf("Readings = %q, want %q", readings, want)
+	}
+}
+
+func TestParseDictionaryInvalid(t *testing.T) {
+	for _, data := range []string{"ping", "ping 苹\nguo 苹", "苹果 ping", "+du 都", "du 都\n+du 都", "du 都\n+dou 都\n+dou 都"} {
+		if _, err := parseDictionary(data); err == nil {
+			t.Errorf("parseDictionary(%q) should fail", data)
+		}
+	}
+}
+
+func TestPinyinFilter(t *testing.T) {
+	input := analysis.TokenStream{
+		{Term: []byte("苹果"), Position: 1, Start: 0, End: 6},
+		{Term: []byte("NFC"), Position: 2, Start: 6, End: 9},
+		{Term: []byte("5G手机"), Position: 3, Start: 9, End: 17},
+		{Term: []byte("都市"), Position: 4, Start: 17, End: 23},
+	}
+
+	tests := []struct {
+		outputs []string
+		want    []string
+	}{
+		{nil, []string{"苹果@1", "pingguo@1", "pg@1", "NFC@2", "5G手机@3", "5gshouji@3", "5gsj@3", "都市@4", "dushi@4", "doushi@4", "ds@4"}},
+		{[]string{OutputInitials}, []string{"pg@1", "NFC@2", "5gsj@3", "ds@4"}},
+		{[]string{OutputOriginal, OutputFull}, []string{"苹果@1", "pingguo@1", "NFC@2", "5G手机@3", "5gshouji@3", "都市@4", "dushi@4", "doushi@4"}},
+	}
+	for _, tt := range tests {
+		f, err := NewPinyinFilter(tt.outputs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, token := range f.Filter(input) {
+			got = append(got, fmt.Sprintf("%s@%d", token.Term, token.Position))
+			if token.Position == 3 && (token.Start != 9 || token.End != 17) {
+				t.Errorf("%s offsets = %d-%d, want 9-17", token.Term, token.Start, token.End)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("outputs %v: tokens = %v, want %v", tt.outputs, got, tt.want)
+		}
+	}
+
+	for _, outputs := range [][]string{{OutputOriginal}, {"pinyin"}} {
+		if _, err := NewPinyinFilter(outputs...); err == nil {
+			t.Errorf("NewPinyinFilter(%v) should fail", outputs)
+		}
+	}
+}
+
+func TestConvertLimit(t *testing.T) {
+	// 每个字都有多个读音时组合数量受限，第一个为使用最常用读音的组合
+	term := "都长乐还"
+	full, initials, ok := convert(term)
+	if !ok {
+		t.Fatal("convert should succeed")
+	}
+	if want := strings.Join(Syllables(term), ""); len(full) != maxReadings || full[0] != want {
+		t.Errorf("full = %q, want %d readings starting with %q", full, maxReadings, want)
+	}
+	if len(initials) != 4 || initials[0] != "dclh" {
+		t.Errorf("initials = %q, want 4 readings starting with dclh", initials)
+	}
+}
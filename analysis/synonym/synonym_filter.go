@@ -0,0 +1,76 @@
+package synonym
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+const Name = "synonym"
+
+// 初始化函数：注册词元过滤器，配置项 synonyms 为同义词组列表
+func init() {
+	registry.RegisterTokenFilter(Name, func(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+		list, ok := config["synonyms"].([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fmt.Errorf("必须指定 synonyms")
+		}
+		groups := make([]string, 0, len(list))
+		for _, item := range list {
+			group, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("同义词组必须是字符串: %v", item)
+			}
+			groups = append(groups, group)
+		}
+		return NewSynonymFilter(groups), nil
+	})
+}
+
+type SynonymFilter struct {
+	synonyms map[string][]string // 词 -> 同组的其他词
+}
+
+// 确保SynonymFilter实现bleve的TokenFilter接口
+var _ analysis.TokenFilter = &SynonymFilter{}
+
+// NewSynonymFilter 每组为逗号分隔的同义词，如 "手机, 移动电话"，组内的词互为同义词
+func NewSynonymFilter(groups []string) *SynonymFilter {
+	f := &SynonymFilter{synonyms: make(map[string][]string)}
+	for _, group := range groups {
+		var words []string
+		for _, word := range strings.Split(group, ",") {
+			if word = strings.TrimSpace(word); word != "" {
+				words = append(words, word)
+			}
+		}
+		for _, word := range words {
+			for _, synonym := range words {
+				if synonym != word {
+					f.synonyms[word] = append(f.synonyms[word], synonym)
+				}
+			}
+		}
+	}
+	return f
+}
+
+// Filter 在每个词元之后加入其同义词，同义词与原词元的位置和偏移相同
+func (f *SynonymFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	output := make(analysis.TokenStream, 0, len(input))
+	for _, token := range input {
+		output = append(output, token)
+		for _, synonym := range f.synonyms[string(token.Term)] {
+			output = append(output, &analysis.Token{
+				Term:     []byte(synonym),
+				Start:    token.Start,
+				End:      token.End,
+				Position: token.Position,
+				Type:     token.Type,
+			})
+		}
+	}
+	return output
+}
@@ -0,0 +1,31 @@
+package synonym
+
+import (
+	"testing"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+)
+
+func TestSynonymFilter(t *testing.T) {
+	input := analysis.TokenStream{
+		{Term: []byte("小米"), Position: 1, Start: 0, End: 6},
+		{Term: []byte("手机"), Position: 2, Start: 6, End: 12},
+	}
+	tokens := NewSynonymFilter([]string{"手机, 移动电话 ,电话机"}).Filter(input)
+
+	want := []struct {
+		term     string
+		position int
+	}{{"小米", 1}, {"手机", 2}, {"移动电话", 2}, {"电话机", 2}}
+	if len(tokens) != len(want) {
+		t.Fatalf("tokens = %v", tokens)
+	}
+	for i, token := range tokens {
+		if string(token.Term) != want[i].term || token.Position != want[i].position {
+			t.Errorf("token %d = %s@%d, want %s@%d", i, token.Term, token.Position, want[i].term, want[i].position)
+		}
+	}
+	if tokens[2].Start != 6 || tokens[2].End != 12 {
+		t.Errorf("synonym offsets = %d-%d, want 6-12", tokens[2].Start, tokens[2].End)
+	}
+}
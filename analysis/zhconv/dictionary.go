@@ -0,0 +1,88 @@
+package zhconv
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 内置的简繁转换表，按字转换，不处理词语用法的差异(如 软件/軟體)
+//
+//go:embed zhconv.dict
+var dictionaryData string
+
+type dictionary struct {
+	t2s map[rune]rune // 繁体 -> 简体
+	s2t map[rune]rune // 简体 -> 繁体
+}
+
+// 转换表在第一次使用时解析，内置转换表不合法时 panic
+var loadDictionary = sync.OnceValue(func() *dictionary {
+	d, err := parseDictionary(dictionaryData)
+	if err != nil {
+		panic(err)
+	}
+	return d
+})
+
+// 解析转换表，# 开头的行为注释；每行为一个简体字及其对应的繁体字，第一个繁体字用于简体转繁体
+// 转换前后的字符 UTF-8 编码长度必须相同，这样转换不会改变文本中的字节偏移
+func parseDictionary(data string) (*dictionary, error) {
+	d := &dictionary{t2s: make(map[rune]rune), s2t: make(map[rune]rune)}
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 {
+			return nil, fmt.Errorf("简繁转换表第 %d 行格式错误: %q", i+1, line)
+		}
+
+		simplified, _ := utf8.DecodeRuneInString(fields[0])
+		if _, exists := d.s2t[simplified]; exists {
+			return nil, fmt.Errorf("简繁转换表第 %d 行的简体字 %c 重复", i+1, simplified)
+		}
+		for j, traditional := range []rune(fields[1]) {
+			if utf8.RuneLen(traditional) != utf8.RuneLen(simplified) {
+				return nil, fmt.Errorf("简繁转换表第 %d 行的 %c 与 %c 编码长度不同", i+1, traditional, simplified)
+			}
+			if j == 0 {
+				d.s2t[simplified] = traditional
+			}
+			if traditional == simplified {
+				continue
+			}
+			if _, exists := d.t2s[traditional]; exists {
+				return nil, fmt.Errorf("简繁转换表第 %d 行的繁体字 %c 重复", i+1, traditional)
+			}
+			d.t2s[traditional] = simplified
+		}
+	}
+	return d, nil
+}
+
+// ToSimplified 将文本中的繁体字转为简体字，其他字符保持不变
+func ToSimplified(text string) string {
+	return string(convert([]byte(text), loadDictionary().t2s))
+}
+
+// ToTraditional 将文本中的简体字转为繁体字，其他字符保持不变
+func ToTraditional(text string) string {
+	return string(convert([]byte(text), loadDictionary().s2t))
+}
+
+// 逐字替换，替换前后编码长度相同，不合法的 UTF-8 字节原样保留，结果与输入的长度一致
+func convert(input []byte, table map[rune]rune) []byte {
+	output := make([]byte, len(input))
+	copy(output, input)
+	for i := 0; i < len(output); {
+		r, size := utf8.DecodeRune(output[i:])
+		if converted, ok := table[r]; ok {
+			utf8.EncodeRune(output[i:], converted)
+		}
+		i += size
+	}
+	return output
+}
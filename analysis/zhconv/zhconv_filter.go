@@ -0,0 +1,54 @@
+package zhconv
+
+import (
+	"fmt"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+const (
+	T2SName = "zhconv_t2s" // 繁体转简体
+	S2TName = "zhconv_s2t" // 简体转繁体
+)
+
+// 转换方向
+const (
+	ToSimplifiedDirection  = "t2s"
+	ToTraditionalDirection = "s2t"
+)
+
+// 初始化函数：注册两个方向的字符过滤器
+func init() {
+	for name, direction := range map[string]string{T2SName: ToSimplifiedDirection, S2TName: ToTraditionalDirection} {
+		registry.RegisterCharFilter(name, func(config map[string]interface{}, cache *registry.Cache) (analysis.CharFilter, error) {
+			return NewConvertCharFilter(direction)
+		})
+	}
+}
+
+// ConvertCharFilter 在分词之前按内置转换表逐字进行简繁转换，
+// 繁简混用的文本转为同一种写法后，蘋果 和 苹果 可以互相匹配
+// 转换不改变文本的字节长度，词元偏移和高亮位置仍然对应原文
+type ConvertCharFilter struct {
+	table map[rune]rune
+}
+
+// 确保ConvertCharFilter实现bleve的CharFilter接口
+var _ analysis.CharFilter = &ConvertCharFilter{}
+
+// NewConvertCharFilter 指定转换方向 t2s(繁体转简体) 或 s2t(简体转繁体)
+func NewConvertCharFilter(direction string) (*ConvertCharFilter, error) {
+	switch direction {
+	case ToSimplifiedDirection:
+		return &ConvertCharFilter{table: loadDictionary().t2s}, nil
+	case ToTraditionalDirection:
+		return &ConvertCharFilter{table: loadDictionary().s2t}, nil
+	default:
+		return nil, fmt.Errorf("不支持的简繁转换方向: %q", direction)
+	}
+}
+
+func (f *ConvertCharFilter) Filter(input []byte) []byte {
+	return convert(input, f.table)
+}
@@ -0,0 +1,65 @@
+package zhconv
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDictionary(t *testing.T) {
+	d, err := parseDictionary(dictionaryData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for traditional, simplified := range d.t2s {
+		if utf8.RuneLen(traditional) != utf8.RuneLen(simplified) {
+			t.Errorf("%c -> %c changes length", traditional, simplified)
+		}
+	}
+
+	tests := []struct {
+		traditional string
+		simplified  string
+	}{
+		{"蘋果手機", "苹果手机"},
+		{"臺灣的銀行", "台湾的银行"},
+		{"頭髮與麵條", "头发与面条"},
+		{"iPhone 15 黑色", "iPhone 15 黑色"},
+	}
+	for _, tt := range tests {
+		if got := ToSimplified(tt.traditional); got != tt.simplified {
+			t.Errorf("ToSimplified(%q) = %q, want %q", tt.traditional, got, tt.simplified)
+		}
+	}
+
+	// 简体转繁体使用最常用的繁体字，简繁同形的字保持不变
+	if got, want := ToTraditional("苹果手机的面板"), "蘋果手機的面板"; got != want {
+		t.Errorf("ToTraditional = %q, want %q", got, want)
+	}
+}
+
+func TestParseDictionaryInvalid(t *testing.T) {
+	for _, data := range []string{"苹", "苹果 蘋", "苹 蘋\n苹 蘋", "苹 蘋\n萍 蘋", "a 蘋"} {
+		if _, err := parseDictionary(data); err == nil {
+			t.Errorf("parseDictionary(%q) should fail", data)
+		}
+	}
+}
+
+func TestConvertCharFilter(t *testing.T) {
+	f, err := NewConvertCharFilter(ToSimplifiedDirection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []byte("蘋果\xff手機")
+	got := f.Filter(input)
+	if string(got) != "苹果\xff手机" {
+		t.Errorf("Filter = %q", got)
+	}
+	if string(input) != "蘋果\xff手機" {
+		t.Error("Filter modified its input")
+	}
+
+	if _, err := NewConvertCharFilter("t2hk"); err == nil {
+		t.Error("unknown direction accepted")
+	}
+}
@@ -1,18 +1,222 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-search/analysis/jieba"
+	"go-search/model"
 	"go-search/service"
-	"log"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
+// 服务配置
+type Config struct {
+	Server   ServerConfig  `yaml:"server" toml:"server"`
+	DataDir  string        `yaml:"data_dir" toml:"data_dir"`   // 索引数据目录
+	Storage  string        `yaml:"storage" toml:"storage"`     // 默认存储方式: disk, memory(所有索引只保存在内存中)
+	LogLevel string        `yaml:"log_level" toml:"log_level"` // 日志级别: debug, info, warn, error
+	Batch    BatchConfig   `yaml:"batch" toml:"batch"`
+	Stats    StatsConfig   `yaml:"stats" toml:"stats"`
+	Jieba    JiebaConfig   `yaml:"jieba" toml:"jieba"`
+	Indexes  []IndexConfig `yaml:"indexes" toml:"indexes"` // 启动时创建或打开的索引
+}
+
+// HTTP服务配置
+type ServerConfig struct {
+	Addr            string   `yaml:"addr" toml:"addr"`                         // 监听地址
+	ReadTimeout     Duration `yaml:"read_timeout" toml:"read_timeout"`         // 读取整个请求(含请求体)的超时时间，0表示不限制
+	WriteTimeout    Duration `yaml:"write_timeout" toml:"write_timeout"`       // 写入响应的超时时间，0表示不限制
+	IdleTimeout     Duration `yaml:"idle_timeout" toml:"idle_timeout"`         // 空闲连接的超时时间，0表示不限制
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"` // 退出时等待处理中请求结束的最长时间，超时后开始关闭索引，关闭时仍会等待正在进行的索引读写结束
+}
+
+// 批量操作配置
+type BatchConfig struct {
+	BulkSize    int `yaml:"bulk_size" toml:"bulk_size"`         // 批量写入默认每批提交的操作数量
+	ByQuerySize int `yaml:"by_query_size" toml:"by_query_size"` // 按查询删除/更新默认每批处理的文档数量
+}
+
+// 统计配置
+type StatsConfig struct {
+	ScanSize int `yaml:"scan_size" toml:"scan_size"` // 数字字段范围分布统计时扫描的最大文档数量
+}
+
+// jieba 分词词典配置，为空的项使用 gojieba 自带的词典
+type JiebaConfig struct {
+	DictPath      string `yaml:"dict_path" toml:"dict_path"`             // 主词典
+	HMMPath       string `yaml:"hmm_path" toml:"hmm_path"`               // HMM 模型
+	UserDictPath  string `yaml:"user_dict_path" toml:"user_dict_path"`   // 用户词典，修改后可以通过接口重新加载
+	IDFPath       string `yaml:"idf_path" toml:"idf_path"`               // IDF 词典，用于关键词提取
+	StopWordsPath string `yaml:"stop_words_path" toml:"stop_words_path"` // 停用词，用于关键词提取
+}
+
+// 对应的 jieba 词典路径
+func (c JiebaConfig) Dictionaries() jieba.Dictionaries {
+	return jieba.Dictionaries{
+		DictPath:      c.DictPath,
+		HMMPath:       c.HMMPath,
+		UserDictPath:  c.UserDictPath,
+		IDFPath:       c.IDFPath,
+		StopWordsPath: c.StopWordsPath,
+	}
+}
+
+// 启动时初始化的索引
+type IndexConfig struct {
+	Name             string                   `yaml:"name" toml:"name"`
+	Fields           map[string]interface{}   `yaml:"fields" toml:"fields"`                       // 字段配置，与创建索引接口一致，可以是字符串简写或对象
+	Storage          string                   `yaml:"storage" toml:"storage"`                     // 存储方式，为空时使用全局配置
+	Dynamic          interface{}              `yaml:"dynamic" toml:"dynamic"`                     // 未定义字段的处理方式: true / false / strict
+	DynamicTemplates []map[string]interface{} `yaml:"dynamic_templates" toml:"dynamic_templates"` // 动态模板，与创建索引接口一致
+	Analysis         map[string]interface{}   `yaml:"analysis" toml:"analysis"`                   // 自定义分析器，与创建索引接口一致
+	Keywords         []map[string]interface{} `yaml:"keywords" toml:"keywords"`                   // 关键词提取，与创建索引接口一致
+}
+
+// 解析字段配置
+func (c IndexConfig) FieldMappings() (map[string]model.FieldMapping, error) {
+	if len(c.Fields) == 0 {
+		return nil, nil
+	}
+	var fields map[string]model.FieldMapping
+	if err := convertJSON(c.Fields, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// 创建索引的选项
+func (c IndexConfig) IndexOptions() (model.IndexOptions, error) {
+	opts := model.IndexOptions{Storage: c.Storage}
+	if c.Dynamic != nil {
+		var dynamic model.Dynamic
+		if err := convertJSON(c.Dynamic, &dynamic); err != nil {
+			return opts, err
+		}
+		opts.Dynamic = string(dynamic)
+	}
+	if len(c.DynamicTemplates) > 0 {
+		if err := convertJSON(c.DynamicTemplates, &opts.DynamicTemplates); err != nil {
+			return opts, fmt.Errorf("dynamic_templates 不合法: %v", err)
+		}
+	}
+	if len(c.Analysis) > 0 {
+		if err := convertJSON(c.Analysis, &opts.Analysis); err != nil {
+			return opts, fmt.Errorf("analysis 不合法: %v", err)
+		}
+	}
+	if len(c.Keywords) > 0 {
+		if err := convertJSON(c.Keywords, &opts.Keywords); err != nil {
+			return opts, fmt.Errorf("keywords 不合法: %v", err)
+		}
+	}
+	return opts, nil
+}
+
+// 通过JSON转换配置值，与创建索引接口使用相同的解析规则，出现未知的键时返回错误
+func convertJSON(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
+// 时间间隔，配置中使用 "30s"、"1m" 等格式
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("时间间隔格式不合法: %s", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// 时间间隔对应的 time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// 默认配置
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			IdleTimeout:     Duration(120 * time.Second),
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		DataDir:  service.DefaultDataDir,
+		Storage:  model.StorageDisk,
+		LogLevel: "info",
+		Batch: BatchConfig{
+			BulkSize:    service.DefaultBulkBatchSize,
+			ByQuerySize: service.DefaultBulkBatchSize,
+		},
+		Stats: StatsConfig{
+			ScanSize: service.DefaultStatsScanSize,
+		},
+		// 默认初始化一个名为"default"的索引
+		Indexes: []IndexConfig{{Name: "default"}},
+	}
+}
+
 // 初始化配置
-func Init() {
-	// 默认初始化一个名为"default"的索引
-	if err := service.InitIndex("default", nil); err != nil {
-		log.Printf("默认索引初始化失败: %v", err)
+// 依次加载默认配置、配置文件(path为空时跳过)和环境变量，设置日志级别，然后按配置创建 Engine 并加载索引
+func Init(path string) (*Config, *service.Engine, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	level, _ := parseLogLevel(cfg.LogLevel)
+	slog.SetLogLoggerLevel(level)
+	if level > slog.LevelDebug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// 需在打开索引之前设置，索引创建分词器时使用
+	if err := jieba.SetDictionaries(cfg.Jieba.Dictionaries()); err != nil {
+		return nil, nil, fmt.Errorf("加载 jieba 词典失败: %v", err)
+	}
+
+	engine := service.NewEngine(cfg.EngineOptions()...)
+	for _, index := range cfg.Indexes {
+		// 字段配置已在加载时校验
+		fields, _ := index.FieldMappings()
+		opts, _ := index.IndexOptions()
+		if err := engine.InitIndex(index.Name, fields, opts); err != nil {
+			slog.Error("索引初始化失败", "index", index.Name, "error", err)
+		}
 	}
 	// 加载所有已存在的索引
-	if err := service.LoadAllIndexes(); err != nil {
-		log.Printf("加载索引失败: %v", err)
+	if err := engine.LoadAllIndexes(); err != nil {
+		slog.Error("加载索引失败", "error", err)
+	}
+
+	return cfg, engine, nil
+}
+
+// 配置对应的 Engine 构造选项
+func (cfg *Config) EngineOptions() []service.Option {
+	opts := []service.Option{
+		service.WithDataDir(cfg.DataDir),
+		service.WithBulkBatchSize(cfg.Batch.BulkSize),
+		service.WithByQueryBatchSize(cfg.Batch.ByQuerySize),
+		service.WithStatsScanSize(cfg.Stats.ScanSize),
+	}
+	if cfg.Storage == model.StorageMemory {
+		opts = append(opts, service.WithInMemory())
 	}
+	return opts
 }
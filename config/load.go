@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-search/model"
+	"go-search/service"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 环境变量前缀，如 GO_SEARCH_ADDR
+const envPrefix = "GO_SEARCH_"
+
+// 指定配置文件路径的环境变量
+const EnvConfigPath = envPrefix + "CONFIG"
+
+// 可通过环境变量覆盖的配置项
+var envOverrides = map[string]func(cfg *Config, value string) error{
+	"ADDR":                func(cfg *Config, v string) error { cfg.Server.Addr = v; return nil },
+	"READ_TIMEOUT":        func(cfg *Config, v string) error { return cfg.Server.ReadTimeout.UnmarshalText([]byte(v)) },
+	"WRITE_TIMEOUT":       func(cfg *Config, v string) error { return cfg.Server.WriteTimeout.UnmarshalText([]byte(v)) },
+	"IDLE_TIMEOUT":        func(cfg *Config, v string) error { return cfg.Server.IdleTimeout.UnmarshalText([]byte(v)) },
+	"SHUTDOWN_TIMEOUT":    func(cfg *Config, v string) error { return cfg.Server.ShutdownTimeout.UnmarshalText([]byte(v)) },
+	"DATA_DIR":            func(cfg *Config, v string) error { cfg.DataDir = v; return nil },
+	"STORAGE":             func(cfg *Config, v string) error { cfg.Storage = v; return nil },
+	"LOG_LEVEL":           func(cfg *Config, v string) error { cfg.LogLevel = v; return nil },
+	"BULK_BATCH_SIZE":     func(cfg *Config, v string) error { return parseInt(v, &cfg.Batch.BulkSize) },
+	"BY_QUERY_BATCH_SIZE": func(cfg *Config, v string) error { return parseInt(v, &cfg.Batch.ByQuerySize) },
+	"STATS_SCAN_SIZE":     func(cfg *Config, v string) error { return parseInt(v, &cfg.Stats.ScanSize) },
+
+	// jieba 词典路径
+	"JIEBA_DICT_PATH":       func(cfg *Config, v string) error { cfg.Jieba.DictPath = v; return nil },
+	"JIEBA_HMM_PATH":        func(cfg *Config, v string) error { cfg.Jieba.HMMPath = v; return nil },
+	"JIEBA_USER_DICT_PATH":  func(cfg *Config, v string) error { cfg.Jieba.UserDictPath = v; return nil },
+	"JIEBA_IDF_PATH":        func(cfg *Config, v string) error { cfg.Jieba.IDFPath = v; return nil },
+	"JIEBA_STOP_WORDS_PATH": func(cfg *Config, v string) error { cfg.Jieba.StopWordsPath = v; return nil },
+}
+
+// 加载配置：默认配置 < 配置文件 < 环境变量
+// 配置文件根据扩展名按 YAML(.yaml/.yml) 或 TOML(.toml) 解析，包含未知配置项时返回错误
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadEnv(os.Environ(), cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// 读取配置文件，文件中出现的配置项覆盖默认值
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// 空文件视为未做任何配置
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			if strictErr, ok := err.(*toml.StrictMissingError); ok {
+				return fmt.Errorf("解析配置文件 %s 失败: %s", path, strictErr.String())
+			}
+			return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	default:
+		return fmt.Errorf("不支持的配置文件格式: %s", path)
+	}
+
+	return nil
+}
+
+// 使用 GO_SEARCH_ 前缀的环境变量覆盖配置，未知的环境变量返回错误
+func loadEnv(environ []string, cfg *Config) error {
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, envPrefix) || key == EnvConfigPath {
+			continue
+		}
+
+		override, ok := envOverrides[strings.TrimPrefix(key, envPrefix)]
+		if !ok {
+			return fmt.Errorf("未知的环境变量: %s", key)
+		}
+		if err := override(cfg, value); err != nil {
+			return fmt.Errorf("环境变量 %s 不合法: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func parseInt(value string, dst *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("必须为整数")
+	}
+	*dst = n
+	return nil
+}
+
+// 校验配置
+func (cfg *Config) Validate() error {
+	if cfg.Server.Addr == "" {
+		return fmt.Errorf("server.addr 不能为空")
+	}
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 || cfg.Server.ShutdownTimeout < 0 {
+		return fmt.Errorf("server 超时时间不能为负数")
+	}
+	if cfg.DataDir == "" {
+		return fmt.Errorf("data_dir 不能为空")
+	}
+	if !isValidStorage(cfg.Storage, false) {
+		return fmt.Errorf("storage 不合法: %s", cfg.Storage)
+	}
+	if _, err := parseLogLevel(cfg.LogLevel); err != nil {
+		return err
+	}
+	if cfg.Batch.BulkSize <= 0 || cfg.Batch.ByQuerySize <= 0 {
+		return fmt.Errorf("batch 中的批量大小必须为正整数")
+	}
+	if cfg.Stats.ScanSize <= 0 {
+		return fmt.Errorf("stats.scan_size 必须为正整数")
+	}
+
+	names := make(map[string]struct{}, len(cfg.Indexes))
+	for _, index := range cfg.Indexes {
+		if !service.IsValidIndexName(index.Name) {
+			return fmt.Errorf("索引名称不合法: %q", index.Name)
+		}
+		if !isValidStorage(index.Storage, true) {
+			return fmt.Errorf("索引 %s 的 storage 不合法: %s", index.Name, index.Storage)
+		}
+		if cfg.Storage == model.StorageMemory && index.Storage == model.StorageDisk {
+			return fmt.Errorf("storage 为 memory 时索引 %s 不能使用磁盘存储", index.Name)
+		}
+		if _, err := index.FieldMappings(); err != nil {
+			return fmt.Errorf("索引 %s 的字段配置不合法: %v", index.Name, err)
+		}
+		if _, err := index.IndexOptions(); err != nil {
+			return fmt.Errorf("索引 %s 的配置不合法: %v", index.Name, err)
+		}
+		if _, exists := names[index.Name]; exists {
+			return fmt.Errorf("索引 %s 重复配置", index.Name)
+		}
+		names[index.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// 存储方式是否合法，allowEmpty 表示是否允许为空(使用全局配置)
+func isValidStorage(storage string, allowEmpty bool) bool {
+	switch storage {
+	case model.StorageDisk, model.StorageMemory:
+		return true
+	case "":
+		return allowEmpty
+	default:
+		return false
+	}
+}
+
+// 解析日志级别
+func parseLogLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("日志级别不合法: %s", level)
+	}
+	return l, nil
+}
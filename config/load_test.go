@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yamlPath := writeConfig(t, "config.yaml", `
+server:
+  addr: ":9200"
+  read_timeout: 5s
+data_dir: /var/lib/go-search
+batch:
+  bulk_size: 500
+indexes:
+  - name: products
+    fields:
+      title: jieba
+      price: number
+      released:
+        type: date
+        date_layouts: ["2006-01-02"]
+        store: false
+    dynamic: true
+    dynamic_templates:
+      - match: "*_id"
+        mapping: keyword
+    keywords:
+      - field: title
+        top_k: 3
+`)
+	tomlPath := writeConfig(t, "config.toml", `
+data_dir = "/var/lib/go-search"
+
+[server]
+addr = ":9200"
+read_timeout = "5s"
+
+[batch]
+bulk_size = 500
+
+[[indexes]]
+name = "products"
+fields = { title = "jieba", price = "number", released = { type = "date", date_layouts = ["2006-01-02"], store = false } }
+dynamic = true
+dynamic_templates = [{ match = "*_id", mapping = "keyword" }]
+keywords = [{ field = "title", top_k = 3 }]
+`)
+
+	for _, path := range []string{yamlPath, tomlPath} {
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if cfg.Server.Addr != ":9200" || cfg.Server.ReadTimeout.Duration() != 5*time.Second {
+			t.Errorf("%s: server = %+v", path, cfg.Server)
+		}
+		// 未配置的项保留默认值
+		if cfg.Server.IdleTimeout != Default().Server.IdleTimeout || cfg.Batch.ByQuerySize != Default().Batch.ByQuerySize {
+			t.Errorf("%s: defaults not kept: %+v", path, cfg)
+		}
+		if cfg.DataDir != "/var/lib/go-search" || cfg.Batch.BulkSize != 500 {
+			t.Errorf("%s: cfg = %+v", path, cfg)
+		}
+		if len(cfg.Indexes) != 1 || cfg.Indexes[0].Name != "products" {
+			t.Fatalf("%s: indexes = %+v", path, cfg.Indexes)
+		}
+		fields, err := cfg.Indexes[0].FieldMappings()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		released := fields["released"]
+		if fields["title"].Type != "jieba" || released.Type != "date" || len(released.DateLayouts) != 1 || released.Store == nil || *released.Store {
+			t.Errorf("%s: fields = %+v", path, fields)
+		}
+		opts, err := cfg.Indexes[0].IndexOptions()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if opts.Dynamic != "true" || len(opts.DynamicTemplates) != 1 || opts.DynamicTemplates[0].Mapping.Type != "keyword" ||
+			len(opts.Keywords) != 1 || opts.Keywords[0].Field != "title" || opts.Keywords[0].TopK != 3 {
+			t.Errorf("%s: options = %+v", path, opts)
+		}
+	}
+
+	// 环境变量优先于配置文件
+	t.Setenv("GO_SEARCH_ADDR", ":9300")
+	t.Setenv("GO_SEARCH_BULK_BATCH_SIZE", "200")
+	t.Setenv("GO_SEARCH_JIEBA_USER_DICT_PATH", "/etc/go-search/user.dict.utf8")
+	cfg, err := Load(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":9300" || cfg.Batch.BulkSize != 200 || cfg.Jieba.UserDictPath != "/etc/go-search/user.dict.utf8" {
+		t.Errorf("env overrides not applied: %+v", cfg)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{name: "unknown yaml key", file: "c.yaml", content: "server:\n  port: 8080\n", want: "port"},
+		{name: "unknown toml key", file: "c.toml", content: "[server]\nport = 8080\n", want: "port"},
+		{name: "bad duration", file: "c.yaml", content: "server:\n  idle_timeout: soon\n", want: "时间间隔"},
+		{name: "bad log level", file: "c.yaml", content: "log_level: verbose\n", want: "日志级别"},
+		{name: "bad index name", file: "c.yaml", content: "indexes:\n  - name: ../etc\n", want: "索引名称"},
+		{name: "unknown field option", file: "c.yaml", content: "indexes:\n  - name: products\n    fields:\n      title: {type: text, stored: true}\n", want: "stored"},
+		{name: "unknown analysis key", file: "c.yaml", content: "indexes:\n  - name: products\n    analysis:\n      analyzer: {}\n", want: "analyzer"},
+		{name: "unknown analyzer option", file: "c.yaml", content: "indexes:\n  - name: products\n    analysis:\n      analyzers:\n        a: {tokenizer: jieba, filters: [lowercase]}\n", want: "filters"},
+		{name: "unknown keywords option", file: "c.toml", content: "[[indexes]]\nname = \"products\"\n[[indexes.keywords]]\nfield = \"title\"\ntopk = 3\n", want: "topk"},
+		{name: "bad dynamic", file: "c.yaml", content: "indexes:\n  - name: products\n    dynamic: sometimes\n", want: "dynamic"},
+		{name: "unsupported format", file: "c.json", content: "{}", want: "不支持"},
+		{name: "unknown env", file: "c.yaml", env: map[string]string{"GO_SEARCH_PORT": "8080"}, want: "GO_SEARCH_PORT"},
+		{name: "bad env value", file: "c.yaml", env: map[string]string{"GO_SEARCH_STATS_SCAN_SIZE": "many"}, want: "GO_SEARCH_STATS_SCAN_SIZE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := Load(writeConfig(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
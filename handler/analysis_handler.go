@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 重新加载jieba用户词典
+func (h *Handler) ReloadUserDictHandler(c *gin.Context) {
+	reloaded, err := h.engine.ReloadJiebaUserDict()
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error(), "reloaded": reloaded})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "用户词典已重新加载",
+		"reloaded": reloaded,
+		"warning":  "已建立索引的文档不会重新分词，需要重建索引后新的词典才对这些文档生效",
+	})
+}
+
+// 分析请求体，指定 index_name 和 field 时使用字段的分析器，也可以通过 analyzer 直接指定分析器
+type AnalyzeRequest struct {
+	IndexName string `json:"index_name,omitempty"`
+	Field     string `json:"field,omitempty"`
+	Analyzer  string `json:"analyzer,omitempty"`
+	Text      string `json:"text" binding:"required"`
+}
+
+// 查看文本的分词结果
+func (h *Handler) AnalyzeHandler(c *gin.Context) {
+	var req AnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.engine.Analyze(req.IndexName, req.Field, req.Analyzer, req.Text)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// 关键词提取请求体
+type ExtractKeywordsRequest struct {
+	Text string `json:"text" binding:"required"`
+	TopK int    `json:"top_k,omitempty"` // 提取的关键词数量，默认为5
+}
+
+// 按 TF-IDF 提取文本的关键词
+func (h *Handler) ExtractKeywordsHandler(c *gin.Context) {
+	var req ExtractKeywordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	keywords, err := h.engine.ExtractKeywords(req.Text, req.TopK)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keywords": keywords})
+}
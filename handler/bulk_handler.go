@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 批量写入文档 (NDJSON)
+// 可通过查询参数 index_name 指定默认索引，batch_size 指定每批提交的数量
+func (h *Handler) BulkHandler(c *gin.Context) {
+	batchSize := 0 // 使用配置的默认值
+	if s := c.Query("batch_size"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "batch_size 必须为正整数"})
+			return
+		}
+		batchSize = size
+	}
+
+	resp, err := h.engine.Bulk(c.Request.Body, c.Query("index_name"), batchSize)
+	if err != nil {
+		// 读取失败前已提交的操作仍返回其结果
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "took": resp.Took, "errors": resp.Errors, "items": resp.Items})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
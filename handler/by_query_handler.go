@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"go-search/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 按查询删除请求体
+type DeleteByQueryRequest struct {
+	QueryRequest
+	BatchSize int  `json:"batch_size,omitempty"` // 每批处理的文档数量
+	DryRun    bool `json:"dry_run,omitempty"`    // 只统计匹配数量，不做删除
+}
+
+// 按查询删除文档
+func (h *Handler) DeleteByQueryHandler(c *gin.Context) {
+	var req DeleteByQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := model.ByQueryOptions{BatchSize: req.BatchSize, DryRun: req.DryRun}
+	result, err := h.engine.DeleteByQuery(req.IndexName, req.searchQuery(), opts)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// 按查询更新请求体
+type UpdateByQueryRequest struct {
+	QueryRequest
+	Fields    map[string]interface{} `json:"fields" binding:"required"` // 合并到匹配文档中的字段
+	BatchSize int                    `json:"batch_size,omitempty"`      // 每批处理的文档数量
+	DryRun    bool                   `json:"dry_run,omitempty"`         // 只统计匹配数量，不做更新
+}
+
+// 按查询更新文档
+func (h *Handler) UpdateByQueryHandler(c *gin.Context) {
+	var req UpdateByQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := model.ByQueryOptions{BatchSize: req.BatchSize, DryRun: req.DryRun}
+	result, err := h.engine.UpdateByQuery(req.IndexName, req.searchQuery(), req.Fields, opts)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
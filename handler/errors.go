@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"errors"
+	"go-search/service"
+	"net/http"
+)
+
+// 根据业务错误类型返回对应的HTTP状态码
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,13 @@
+package handler
+
+import "go-search/service"
+
+// HTTP 处理器，所有接口都在同一个 Engine 上操作
+type Handler struct {
+	engine *service.Engine
+}
+
+// 创建使用指定 Engine 的处理器
+func New(engine *service.Engine) *Handler {
+	return &Handler{engine: engine}
+}
@@ -0,0 +1,303 @@
+package handler_test
+
+import (
+	"go-search/handler/handlertest"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDocumentLifecycle(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	status := server.Do(http.MethodPost, "/api/index", map[string]interface{}{
+		"index_name": "products",
+		"fields":     map[string]string{"title": "jieba", "price": "number"},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("create index status = %d", status)
+	}
+
+	var info struct {
+		Storage string `json:"storage"`
+	}
+	if status := server.Do(http.MethodGet, "/api/index/products", nil, &info); status != http.StatusOK || info.Storage != "memory" {
+		t.Errorf("get index status = %d, storage = %q", status, info.Storage)
+	}
+
+	bulk := `{"index": {"id": "1"}}
+{"title": "小米手机", "price": 1999}
+{"index": {"id": "2"}}
+{"title": "华为平板", "price": 2999}
+`
+	var bulkResp struct {
+		Errors bool `json:"errors"`
+	}
+	status = server.DoRaw(http.MethodPost, "/api/_bulk?index_name=products", "application/x-ndjson", strings.NewReader(bulk), &bulkResp)
+	if status != http.StatusOK || bulkResp.Errors {
+		t.Fatalf("bulk status = %d, errors = %v", status, bulkResp.Errors)
+	}
+
+	var searchResp struct {
+		Total uint64 `json:"total"`
+		Hits  []struct {
+			ID string `json:"id"`
+		} `json:"hits"`
+	}
+	status = server.Do(http.MethodPost, "/api/search", map[string]interface{}{
+		"index_name": "products",
+		"type":       3,
+		"dsl":        map[string]interface{}{"match": map[string]interface{}{"field": "title", "query": "手机"}},
+	}, &searchResp)
+	if status != http.StatusOK || searchResp.Total != 1 || searchResp.Hits[0].ID != "1" {
+		t.Errorf("search status = %d, resp = %+v", status, searchResp)
+	}
+
+	status = server.Do(http.MethodDelete, "/api/document", map[string]interface{}{"index_name": "products", "id": "1"}, nil)
+	if status != http.StatusOK {
+		t.Errorf("delete status = %d", status)
+	}
+	if status := server.Do(http.MethodGet, "/api/document/products/1", nil, nil); status != http.StatusNotFound {
+		t.Errorf("get deleted document status = %d, want 404", status)
+	}
+}
+
+func TestCreateIndexStorage(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	var resp struct {
+		Error string `json:"error"`
+	}
+	// 内存引擎不能创建磁盘索引
+	status := server.Do(http.MethodPost, "/api/index", map[string]interface{}{"index_name": "products", "storage": "disk"}, &resp)
+	if status != http.StatusBadRequest || resp.Error == "" {
+		t.Errorf("disk storage status = %d, resp = %+v", status, resp)
+	}
+	status = server.Do(http.MethodPost, "/api/index", map[string]interface{}{"index_name": "products", "storage": "tape"}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("unknown storage status = %d, want 400", status)
+	}
+	// 内存索引不支持关闭
+	server.Do(http.MethodPost, "/api/index", map[string]interface{}{"index_name": "products", "storage": "memory"}, nil)
+	if status := server.Do(http.MethodPost, "/api/index/products/close", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("closing a memory index status = %d, want 400", status)
+	}
+	if status := server.Do(http.MethodPost, "/api/index", map[string]interface{}{"index_name": "products"}, nil); status != http.StatusConflict {
+		t.Errorf("create existing index status = %d, want 409", status)
+	}
+}
+
+func TestStrictDynamic(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	status := server.Do(http.MethodPost, "/api/index", map[string]interface{}{
+		"index_name": "products",
+		"fields":     map[string]string{"title": "jieba"},
+		"dynamic":    "strict",
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("create index status = %d", status)
+	}
+
+	var resp struct {
+		Error string `json:"error"`
+	}
+	status = server.Do(http.MethodPost, "/api/document", map[string]interface{}{
+		"index_name": "products",
+		"id":         "1",
+		"fields":     map[string]interface{}{"titel": "小米手机"},
+	}, &resp)
+	if status != http.StatusBadRequest || !strings.Contains(resp.Error, "titel") {
+		t.Errorf("add document status = %d, resp = %+v", status, resp)
+	}
+
+	status = server.Do(http.MethodPost, "/api/index", map[string]interface{}{"index_name": "other", "dynamic": "sometimes"}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("invalid dynamic status = %d, want 400", status)
+	}
+}
+
+func TestReloadUserDict(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	var resp struct {
+		Message string `json:"message"`
+		Warning string `json:"warning"`
+	}
+	status := server.Do(http.MethodPost, "/api/_admin/jieba/reload_user_dict", nil, &resp)
+	if status != http.StatusOK || resp.Warning == "" {
+		t.Errorf("reload status = %d, resp = %+v", status, resp)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	status := server.Do(http.MethodPost, "/api/index", map[string]interface{}{
+		"index_name": "products",
+		"fields":     map[string]string{"title": "zh_synonym"},
+		"analysis": map[string]interface{}{
+			"analyzers":     map[string]interface{}{"zh_synonym": map[string]interface{}{"tokenizer": "jieba", "token_filters": []string{"phone_synonym"}}},
+			"token_filters": map[string]interface{}{"phone_synonym": map[string]interface{}{"type": "synonym", "synonyms": []string{"手机, 移动电话"}}},
+		},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("create index status = %d", status)
+	}
+
+	type token struct {
+		Term     string `json:"term"`
+		Start    int    `json:"start"`
+		End      int    `json:"end"`
+		Position int    `json:"position"`
+		Type     string `json:"type"`
+	}
+	var resp struct {
+		Tokens []token `json:"tokens"`
+	}
+	status = server.Do(http.MethodPost, "/api/_analyze", map[string]interface{}{
+		"index_name": "products", "field": "title", "text": "小米手机",
+	}, &resp)
+	want := []token{
+		{"小米", 0, 6, 1, "ideographic"},
+		{"手机", 6, 12, 2, "ideographic"},
+		{"移动电话", 6, 12, 2, "ideographic"},
+	}
+	if status != http.StatusOK || len(resp.Tokens) != len(want) {
+		t.Fatalf("analyze field status = %d, resp = %+v", status, resp)
+	}
+	for i, tok := range resp.Tokens {
+		if tok != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, tok, want[i])
+		}
+	}
+
+	resp.Tokens = nil
+	status = server.Do(http.MethodPost, "/api/_analyze", map[string]interface{}{"analyzer": "jieba", "text": "ＮＦＣ手机"}, &resp)
+	if status != http.StatusOK || len(resp.Tokens) == 0 || resp.Tokens[0].Term != "n" {
+		t.Errorf("analyze analyzer status = %d, resp = %+v", status, resp)
+	}
+
+	tests := []struct {
+		body map[string]interface{}
+		want int
+	}{
+		{map[string]interface{}{"index_name": "products", "text": "手机"}, http.StatusBadRequest},
+		{map[string]interface{}{"field": "title", "text": "手机"}, http.StatusBadRequest},
+		{map[string]interface{}{"analyzer": "missing", "text": "手机"}, http.StatusBadRequest},
+		{map[string]interface{}{"index_name": "missing", "field": "title", "text": "手机"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if status := server.Do(http.MethodPost, "/api/_analyze", tt.body, nil); status != tt.want {
+			t.Errorf("analyze %v status = %d, want %d", tt.body, status, tt.want)
+		}
+	}
+}
+
+func TestExtractKeywords(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	var resp struct {
+		Keywords []struct {
+			Word   string  `json:"word"`
+			Weight float64 `json:"weight"`
+		} `json:"keywords"`
+	}
+	status := server.Do(http.MethodPost, "/api/_keywords", map[string]interface{}{"text": "蓝牙耳机降噪效果出色，耳机佩戴舒适", "top_k": 2}, &resp)
+	if status != http.StatusOK || len(resp.Keywords) != 2 || resp.Keywords[0].Word != "耳机" || resp.Keywords[0].Weight <= resp.Keywords[1].Weight {
+		t.Errorf("keywords status = %d, resp = %+v", status, resp)
+	}
+
+	if status := server.Do(http.MethodPost, "/api/_keywords", map[string]interface{}{"text": "耳机", "top_k": -1}, nil); status != http.StatusBadRequest {
+		t.Errorf("invalid top_k status = %d", status)
+	}
+}
+
+func TestFilterOnlyBoolQuery(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	server.Do(http.MethodPost, "/api/index", map[string]interface{}{
+		"index_name": "products",
+		"fields":     map[string]string{"title": "jieba", "category": "keyword"},
+	}, nil)
+	bulk := `{"index": {"id": "1"}}
+{"title": "小米手机", "category": "phone"}
+{"index": {"id": "2"}}
+{"title": "华为平板", "category": "tablet"}
+`
+	server.DoRaw(http.MethodPost, "/api/_bulk?index_name=products", "application/x-ndjson", strings.NewReader(bulk), nil)
+
+	// 只有 filter 子句时不计算评分，响应中的评分为 0
+	var resp struct {
+		Total uint64 `json:"total"`
+		Hits  []struct {
+			ID    string  `json:"id"`
+			Score float64 `json:"score"`
+		} `json:"hits"`
+	}
+	status := server.Do(http.MethodPost, "/api/search", map[string]interface{}{
+		"index_name": "products",
+		"type":       3,
+		"dsl": map[string]interface{}{"bool": map[string]interface{}{
+			"filter": []interface{}{map[string]interface{}{"term": map[string]interface{}{"field": "category", "value": "phone"}}},
+		}},
+	}, &resp)
+	if status != http.StatusOK || resp.Total != 1 || resp.Hits[0].ID != "1" || resp.Hits[0].Score != 0 {
+		t.Errorf("search status = %d, resp = %+v", status, resp)
+	}
+}
+
+func TestRangeSearch(t *testing.T) {
+	server := handlertest.NewServer(t)
+
+	server.Do(http.MethodPost, "/api/index", map[string]interface{}{
+		"index_name": "products",
+		"fields":     map[string]string{"title": "jieba", "stock": "number"},
+	}, nil)
+	bulk := `{"index": {"id": "1"}}
+{"title": "小米手机", "stock": 5}
+{"index": {"id": "2"}}
+{"title": "华为平板", "stock": 50}
+`
+	server.DoRaw(http.MethodPost, "/api/_bulk?index_name=products", "application/x-ndjson", strings.NewReader(bulk), nil)
+
+	// 范围查询适用于任意数字字段，与按查询删除/更新的查询条件一致
+	query := map[string]interface{}{"index_name": "products", "type": 2, "field": "stock", "start": 1, "end": 10}
+	var resp struct {
+		Total uint64 `json:"total"`
+	}
+	if status := server.Do(http.MethodPost, "/api/search", query, &resp); status != http.StatusOK || resp.Total != 1 {
+		t.Errorf("search status = %d, total = %d", status, resp.Total)
+	}
+
+	var deleted struct {
+		Matched uint64 `json:"matched"`
+	}
+	if status := server.Do(http.MethodPost, "/api/_delete_by_query", query, &deleted); status != http.StatusOK || deleted.Matched != resp.Total {
+		t.Errorf("delete by query status = %d, matched = %d", status, deleted.Matched)
+	}
+}
+
+func TestSearchErrorStatus(t *testing.T) {
+	server := handlertest.NewServer(t)
+	server.Do(http.MethodPost, "/api/index", map[string]interface{}{"index_name": "products"}, nil)
+
+	tests := []struct {
+		name string
+		path string
+		body map[string]interface{}
+		want int
+	}{
+		{"missing index", "/api/search", map[string]interface{}{"index_name": "missing", "type": 1, "query": "手机"}, http.StatusNotFound},
+		{"invalid dsl", "/api/search", map[string]interface{}{"index_name": "products", "type": 3, "dsl": map[string]interface{}{"bogus": map[string]interface{}{}}}, http.StatusBadRequest},
+		{"empty dsl", "/api/search", map[string]interface{}{"index_name": "products", "type": 3, "dsl": map[string]interface{}{"bool": map[string]interface{}{}}}, http.StatusBadRequest},
+		{"unknown type", "/api/search", map[string]interface{}{"index_name": "products", "type": 4}, http.StatusBadRequest},
+		{"missing index stats", "/api/index/stats", map[string]interface{}{"index_name": "missing"}, http.StatusNotFound},
+		{"missing index document stats", "/api/document/stats", map[string]interface{}{"index_name": "missing"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if status := server.Do(http.MethodPost, tt.path, tt.body, nil); status != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, status, tt.want)
+		}
+	}
+}
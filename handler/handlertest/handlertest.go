@@ -0,0 +1,81 @@
+// handlertest 提供基于内存引擎的接口测试工具
+package handlertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-search/handler"
+	"go-search/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 测试服务，路由与正式服务一致，所有索引只保存在内存中
+type Server struct {
+	*httptest.Server
+	Engine *service.Engine
+	t      testing.TB
+}
+
+// 启动使用内存引擎的测试服务，测试结束后自动关闭服务及所有索引
+func NewServer(t testing.TB, opts ...service.Option) *Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	engine := service.NewEngine(append(opts, service.WithInMemory())...)
+	server := &Server{
+		Server: httptest.NewServer(handler.NewRouter(engine)),
+		Engine: engine,
+		t:      t,
+	}
+	t.Cleanup(func() {
+		server.Close()
+		engine.CloseAll()
+	})
+	return server
+}
+
+// 发送JSON请求，body 不为空时编码为JSON，响应解码到 out(可为nil)，返回响应状态码
+func (s *Server) Do(method, path string, body, out interface{}) int {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("编码请求失败: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	return s.DoRaw(method, path, "application/json", reader, out)
+}
+
+// 发送原始请求体，用于 NDJSON 等非JSON请求
+func (s *Server) DoRaw(method, path, contentType string, body io.Reader, out interface{}) int {
+	s.t.Helper()
+
+	req, err := http.NewRequest(method, s.URL+path, body)
+	if err != nil {
+		s.t.Fatalf("创建请求失败: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s 请求失败: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			s.t.Fatalf("%s %s 解码响应失败: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 列出所有索引
+func (h *Handler) ListIndexesHandler(c *gin.Context) {
+	infos, err := h.engine.ListIndexes()
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indexes": infos})
+}
+
+// 获取索引映射及文档数量
+func (h *Handler) GetIndexHandler(c *gin.Context) {
+	info, err := h.engine.GetIndex(c.Param("name"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// 关闭索引
+func (h *Handler) CloseIndexHandler(c *gin.Context) {
+	if err := h.engine.CloseIndex(c.Param("name")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "索引关闭成功"})
+}
+
+// 重新打开索引
+func (h *Handler) OpenIndexHandler(c *gin.Context) {
+	if err := h.engine.OpenIndex(c.Param("name")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "索引打开成功"})
+}
+
+// 删除索引
+func (h *Handler) DeleteIndexHandler(c *gin.Context) {
+	if err := h.engine.DeleteIndex(c.Param("name")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "索引删除成功"})
+}
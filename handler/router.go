@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"go-search/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 创建注册了所有API路由的Gin路由
+func NewRouter(engine *service.Engine) *gin.Engine {
+	router := gin.Default()
+	h := New(engine)
+
+	// 注册API路由
+	api := router.Group("/api")
+	{
+		api.POST("/index", h.CreateIndexHandler)
+		api.POST("/index/stats", h.GetIndexStatisticsHandler) // 获取索引统计信息
+		api.GET("/indexes", h.ListIndexesHandler)             // 列出所有索引
+		api.GET("/index/:name", h.GetIndexHandler)            // 获取索引映射及文档数量
+		api.POST("/index/:name/close", h.CloseIndexHandler)   // 关闭索引
+		api.POST("/index/:name/open", h.OpenIndexHandler)     // 重新打开索引
+		api.DELETE("/index/:name", h.DeleteIndexHandler)      // 删除索引
+		api.POST("/document", h.AddDocumentHandler)
+		api.POST("/document/stats", h.GetDocumentStatisticsHandler)
+		api.PUT("/document", h.UpdateDocumentHandler)
+		api.DELETE("/document", h.DeleteDocumentHandler)
+		api.GET("/document/:index/:id", h.GetDocumentHandler)               // 获取文档
+		api.POST("/_mget", h.MultiGetDocumentsHandler)                      // 批量获取文档
+		api.POST("/_bulk", h.BulkHandler)                                   // 批量写入文档 (NDJSON)
+		api.POST("/_delete_by_query", h.DeleteByQueryHandler)               // 按查询删除文档
+		api.POST("/_update_by_query", h.UpdateByQueryHandler)               // 按查询更新文档
+		api.POST("/search", h.SearchHandler)                                // 修改为POST方法
+		api.POST("/number/stats", h.GetNumberFieldRangeDistributionHandler) // 获取数字字段范围分布
+		api.POST("/_analyze", h.AnalyzeHandler)                             // 查看文本的分词结果
+		api.POST("/_keywords", h.ExtractKeywordsHandler)                    // 提取文本的关键词
+		api.POST("/_admin/jieba/reload_user_dict", h.ReloadUserDictHandler) // 重新加载jieba用户词典
+	}
+
+	return router
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"go-search/model"
-	"go-search/service"
 	"math"
 	"net/http"
 
@@ -11,8 +10,13 @@ import (
 
 // 创建索引请求体
 type CreateIndexRequest struct {
-	IndexName string            `json:"index_name" binding:"required"`
-	Fields    map[string]string `json:"fields"` // 字段分词器配置
+	IndexName        string                        `json:"index_name" binding:"required"`
+	Fields           map[string]model.FieldMapping `json:"fields"`                                        // 字段类型及分词器配置
+	Storage          string                        `json:"storage" binding:"omitempty,oneof=disk memory"` // 存储方式，默认为disk
+	Dynamic          model.Dynamic                 `json:"dynamic"`                                       // 未定义字段的处理方式: true(默认) / false / "strict"
+	DynamicTemplates []model.DynamicTemplate       `json:"dynamic_templates"`                             // 为未定义的字段按名称或类型选择映射
+	Analysis         *model.Analysis               `json:"analysis"`                                      // 自定义分析器，字段通过 analyzer 引用
+	Keywords         []model.KeywordExtraction     `json:"keywords"`                                      // 写入文档时从文本字段提取关键词
 }
 
 // 添加文档请求体
@@ -20,31 +24,57 @@ type AddDocumentRequest struct {
 	IndexName string                 `json:"index_name" binding:"required"`
 	ID        string                 `json:"id" binding:"required"`
 	Fields    map[string]interface{} `json:"fields" binding:"required"`
+	IfVersion *uint64                `json:"if_version"` // 可选，文档当前版本不一致时返回409，0表示文档必须不存在
+}
+
+// 查询条件请求体，搜索及按查询删除/更新共用
+type QueryRequest struct {
+	IndexName string       `json:"index_name" binding:"required"`
+	Type      int          `json:"type" binding:"required"` // 1: 普通搜索, 2: 范围查询, 3: 结构化查询
+	Query     string       `json:"query" binding:"required_if=Type 1"`
+	Field     string       `json:"field" binding:"required_if=Type 2"`
+	Start     float64      `json:"start" binding:"required_if=Type 2"`
+	End       float64      `json:"end" binding:"required_if=Type 2"`
+	DSL       *model.Query `json:"dsl" binding:"required_if=Type 3"` // 结构化查询DSL
+}
+
+func (r QueryRequest) searchQuery() model.SearchQuery {
+	return model.SearchQuery{
+		Type:  r.Type,
+		Query: r.Query,
+		Field: r.Field,
+		Start: r.Start,
+		End:   r.End,
+		DSL:   r.DSL,
+	}
 }
 
 // 搜索请求体 (新增)
 type SearchRequest struct {
-	IndexName string  `json:"index_name" binding:"required"`
-	Type      int     `json:"type" binding:"required"` // 1: 普通搜索, 2: 范围查询
-	Query     string  `json:"query" binding:"required_if=Type 1"`
-	Field     string  `json:"field" binding:"required_if=Type 2"`
-	Start     float64 `json:"start" binding:"required_if=Type 2"`
-	End       float64 `json:"end" binding:"required_if=Type 2"`
-	Page      int     `json:"page,omitempty"`    // 可选分页参数
-	Size      int     `json:"size,omitempty"`    // 可选每页数量
-	SortBy    string  `json:"sort_by,omitempty"` // 可选排序字段
+	QueryRequest
+	Highlight *model.HighlightRequest       `json:"highlight,omitempty"` // 可选高亮配置
+	Facets    map[string]model.FacetRequest `json:"facets,omitempty"`    // 可选分面统计
+	Page      int                           `json:"page,omitempty"`      // 可选分页参数
+	Size      int                           `json:"size,omitempty"`      // 可选每页数量
+	SortBy    string                        `json:"sort_by,omitempty"`   // 可选排序字段
 }
 
 // 创建索引
-func CreateIndexHandler(c *gin.Context) {
+func (h *Handler) CreateIndexHandler(c *gin.Context) {
 	var req CreateIndexRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := service.InitIndex(req.IndexName, req.Fields); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.engine.InitIndex(req.IndexName, req.Fields, model.IndexOptions{
+		Storage:          req.Storage,
+		Dynamic:          string(req.Dynamic),
+		DynamicTemplates: req.DynamicTemplates,
+		Analysis:         req.Analysis,
+		Keywords:         req.Keywords,
+	}); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -57,16 +87,16 @@ type GetIndexStatisticsHandlerRequest struct {
 }
 
 // 获取索引统计信息
-func GetIndexStatisticsHandler(c *gin.Context) {
+func (h *Handler) GetIndexStatisticsHandler(c *gin.Context) {
 	var req GetIndexStatisticsHandlerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	stat, err := service.GetIndexStatistics(req.IndexName)
+	stat, err := h.engine.GetIndexStatistics(req.IndexName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -74,7 +104,7 @@ func GetIndexStatisticsHandler(c *gin.Context) {
 }
 
 // 添加文档
-func AddDocumentHandler(c *gin.Context) {
+func (h *Handler) AddDocumentHandler(c *gin.Context) {
 	var req AddDocumentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -86,12 +116,13 @@ func AddDocumentHandler(c *gin.Context) {
 		Fields: req.Fields,
 	}
 
-	if err := service.AddDocument(req.IndexName, doc); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result, err := h.engine.AddDocument(req.IndexName, doc, req.IfVersion)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "文档添加成功"})
+	c.JSON(http.StatusOK, gin.H{"message": "文档添加成功", "_version": result.Version, "_seq_no": result.SeqNo})
 }
 
 // 获取文档统计信息请求体
@@ -100,16 +131,16 @@ type GetDocumentStatisticsHandlerRequest struct {
 }
 
 // 获取文档统计信息
-func GetDocumentStatisticsHandler(c *gin.Context) {
+func (h *Handler) GetDocumentStatisticsHandler(c *gin.Context) {
 	var req GetDocumentStatisticsHandlerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	stat, err := service.GetTermFrequencyRanking(req.IndexName)
+	stat, err := h.engine.GetTermFrequencyRanking(req.IndexName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -121,10 +152,12 @@ type UpdateDocumentRequest struct {
 	IndexName string                 `json:"index_name" binding:"required"`
 	ID        string                 `json:"id" binding:"required"`
 	Fields    map[string]interface{} `json:"fields" binding:"required"`
+	Mode      string                 `json:"mode" binding:"omitempty,oneof=replace merge upsert"` // 默认为replace
+	IfVersion *uint64                `json:"if_version"`                                          // 可选，文档当前版本不一致时返回409
 }
 
 // 更新文档
-func UpdateDocumentHandler(c *gin.Context) {
+func (h *Handler) UpdateDocumentHandler(c *gin.Context) {
 	var req UpdateDocumentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -136,38 +169,85 @@ func UpdateDocumentHandler(c *gin.Context) {
 		Fields: req.Fields,
 	}
 
-	if err := service.UpdateDocument(req.IndexName, doc); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result, err := h.engine.UpdateDocument(req.IndexName, doc, req.Mode, req.IfVersion)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "文档更新成功"})
+	c.JSON(http.StatusOK, gin.H{"message": "文档更新成功", "_version": result.Version, "_seq_no": result.SeqNo})
 }
 
 // 删除文档请求体
 type DeleteDocumentRequest struct {
-	IndexName string `json:"index_name" binding:"required"`
-	ID        string `json:"id" binding:"required"`
+	IndexName string  `json:"index_name" binding:"required"`
+	ID        string  `json:"id" binding:"required"`
+	IfVersion *uint64 `json:"if_version"` // 可选，文档当前版本不一致时返回409
 }
 
 // 删除文档
-func DeleteDocumentHandler(c *gin.Context) {
+func (h *Handler) DeleteDocumentHandler(c *gin.Context) {
 	var req DeleteDocumentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := service.DeleteDocument(req.IndexName, req.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result, err := h.engine.DeleteDocument(req.IndexName, req.ID, req.IfVersion)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "文档删除成功", "_seq_no": result.SeqNo})
+}
+
+// 获取文档
+func (h *Handler) GetDocumentHandler(c *gin.Context) {
+	doc, err := h.engine.GetDocument(c.Param("index"), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// 批量获取文档请求体，可通过 ids 获取同一索引下的文档，或通过 docs 指定每个文档所在的索引
+type MultiGetDocumentsRequest struct {
+	IndexName string              `json:"index_name"`
+	IDs       []string            `json:"ids"`
+	Docs      []model.DocumentRef `json:"docs"`
+}
+
+// 批量获取文档
+func (h *Handler) MultiGetDocumentsHandler(c *gin.Context) {
+	var req MultiGetDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refs := make([]model.DocumentRef, 0, len(req.IDs)+len(req.Docs))
+	for _, id := range req.IDs {
+		refs = append(refs, model.DocumentRef{IndexName: req.IndexName, ID: id})
+	}
+	for _, ref := range req.Docs {
+		if ref.IndexName == "" {
+			ref.IndexName = req.IndexName
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids 和 docs 不能同时为空"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "文档删除成功"})
+	c.JSON(http.StatusOK, gin.H{"docs": h.engine.MultiGetDocuments(refs)})
 }
 
 // 搜索文档 (修改为JSON请求)
-func SearchHandler(c *gin.Context) {
+func (h *Handler) SearchHandler(c *gin.Context) {
 	var req SearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -182,33 +262,26 @@ func SearchHandler(c *gin.Context) {
 		req.Size = 10
 	}
 
-	if req.Field == "price" && req.Start != req.End {
-		result, err := service.RangeSearch(req.IndexName, req.Field, req.Start, req.End, req.Page, req.Size, req.SortBy)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"total": result.Total,
-			"page":  req.Page,
-			"size":  req.Size,
-			"hits":  result.Hits,
-		})
-		return
+	opts := model.SearchOptions{
+		Page:      req.Page,
+		Size:      req.Size,
+		SortBy:    req.SortBy,
+		Highlight: req.Highlight,
+		Facets:    req.Facets,
 	}
 
-	result, err := service.Search(req.IndexName, req.Query, req.Page, req.Size, req.SortBy)
+	result, err := h.engine.SearchByQuery(req.IndexName, req.searchQuery(), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"total": result.Total,
-		"page":  req.Page,
-		"size":  req.Size,
-		"hits":  result.Hits,
+		"total":  result.Total,
+		"page":   req.Page,
+		"size":   req.Size,
+		"hits":   result.Hits,
+		"facets": result.Facets,
 	})
 }
 
@@ -220,7 +293,7 @@ type GetNumberFieldRangeDistributionHandlerRequest struct {
 }
 
 // 获取统计指定数字字段的范围分布
-func GetNumberFieldRangeDistributionHandler(c *gin.Context) {
+func (h *Handler) GetNumberFieldRangeDistributionHandler(c *gin.Context) {
 	var req GetNumberFieldRangeDistributionHandlerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -232,9 +305,9 @@ func GetNumberFieldRangeDistributionHandler(c *gin.Context) {
 	ranges = append(ranges, [2]float64{ranges[len(ranges)-1][1], math.Inf(1)})
 	// 补上一个无穷小的范围
 	ranges = append([][2]float64{{math.Inf(-1), ranges[0][0]}}, ranges...)
-	dist, err := service.GetNumberFieldRangeDistribution("products", "price", ranges)
+	dist, err := h.engine.GetNumberFieldRangeDistribution("products", "price", ranges)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
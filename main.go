@@ -1,35 +1,80 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"go-search/config"
 	"go-search/handler"
+	"log"
 	"log/slog"
-
-	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
+	configPath := flag.String("config", os.Getenv(config.EnvConfigPath), "配置文件路径 (YAML 或 TOML)")
+	flag.Parse()
+
 	// 初始化配置
-	config.Init()
-	slog.Info("Server started on port 8080")
+	cfg, engine, err := config.Init(*configPath)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
 
 	// 创建Gin路由
-	router := gin.Default()
-
-	// 注册API路由
-	api := router.Group("/api")
-	{
-		api.POST("/index", handler.CreateIndexHandler)
-		api.POST("/index/stats", handler.GetIndexStatisticsHandler) // 获取索引统计信息
-		api.POST("/document", handler.AddDocumentHandler)
-		api.POST("/document/stats", handler.GetDocumentStatisticsHandler)
-		api.PUT("/document", handler.UpdateDocumentHandler)
-		api.DELETE("/document", handler.DeleteDocumentHandler)
-		api.POST("/search", handler.SearchHandler)                                // 修改为POST方法
-		api.POST("/number/stats", handler.GetNumberFieldRangeDistributionHandler) // 获取数字字段范围分布
-	}
+	router := handler.NewRouter(engine)
 
 	// 启动服务器
-	slog.Info("Server started on port 8080")
-	router.Run(":8080")
+	server := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout.Duration(),
+		WriteTimeout: cfg.Server.WriteTimeout.Duration(),
+		IdleTimeout:  cfg.Server.IdleTimeout.Duration(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server started", "addr", cfg.Server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		slog.Error("服务启动失败", "error", err)
+		exitCode = 1
+	case <-ctx.Done():
+		// 恢复默认信号处理，再次收到信号时直接退出
+		stop()
+		shutdown(server, cfg.Server.ShutdownTimeout.Duration())
+	}
+
+	// 关闭所有索引，确保数据落盘
+	if err := engine.CloseAll(); err != nil {
+		slog.Error("关闭索引失败", "error", err)
+		exitCode = 1
+	}
+	slog.Info("Server stopped")
+	os.Exit(exitCode)
+}
+
+// 停止接收新请求，并在 timeout 内(0表示不限制)等待处理中的请求结束
+func shutdown(server *http.Server, timeout time.Duration) {
+	slog.Info("Shutting down server", "timeout", timeout)
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("等待处理中的请求结束超时", "error", err)
+	}
 }
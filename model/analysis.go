@@ -0,0 +1,117 @@
+package model
+
+// 自定义分词器类型
+const (
+	TokenizerJieba      = "jieba"      // 结巴中文分词
+	TokenizerUnicode    = "unicode"    // 按 Unicode 单词边界切分
+	TokenizerWhitespace = "whitespace" // 按空白字符切分
+	TokenizerNgram      = "ngram"      // 按空白切分后生成字符 n-gram
+	TokenizerRegexp     = "regexp"     // 正则表达式匹配的内容作为词元
+)
+
+// 自定义词元过滤器类型
+const (
+	TokenFilterLowercase = "lowercase"  // 转为小写
+	TokenFilterCJKWidth  = "cjk_width"  // 全角转半角
+	TokenFilterStop      = "stop"       // 去除停用词
+	TokenFilterStemmer   = "stemmer"    // 词干提取
+	TokenFilterEdgeNgram = "edge_ngram" // 生成词元的前缀
+	TokenFilterLength    = "length"     // 去除过长或过短的词元
+	TokenFilterSynonym   = "synonym"    // 在相同位置加入同义词
+	TokenFilterPOS       = "pos"        // 按 jieba 标注的词性保留或去除词元
+	TokenFilterPinyin    = "pinyin"     // 在相同位置加入拼音全拼和首字母
+)
+
+// 分词前应用的字符过滤器类型
+const (
+	CharFilterT2S = "t2s" // 繁体字转为简体字
+	CharFilterS2T = "s2t" // 简体字转为繁体字
+)
+
+// 自定义分析配置，注册到索引映射中，只对该索引生效
+// 字段的 analyzer 可以引用 analyzers 中定义的名称
+type Analysis struct {
+	Analyzers    map[string]AnalyzerDefinition    `json:"analyzers,omitempty"`
+	Tokenizers   map[string]TokenizerDefinition   `json:"tokenizers,omitempty"`
+	TokenFilters map[string]TokenFilterDefinition `json:"token_filters,omitempty"`
+}
+
+func (a *Analysis) UnmarshalJSON(data []byte) error {
+	type analysis Analysis
+	return decodeStrict(data, (*analysis)(a))
+}
+
+// 自定义分析器，由分词前的字符过滤器、一个分词器和按顺序应用的词元过滤器组成
+// 分词器和词元过滤器可以是 tokenizers/token_filters 中定义的名称，
+// 也可以直接使用无需参数的类型，如 jieba、unicode、whitespace、ngram、lowercase、cjk_width、pinyin
+type AnalyzerDefinition struct {
+	CharFilters  []string `json:"char_filters,omitempty"` // t2s 或 s2t
+	Tokenizer    string   `json:"tokenizer"`
+	TokenFilters []string `json:"token_filters,omitempty"`
+}
+
+func (a *AnalyzerDefinition) UnmarshalJSON(data []byte) error {
+	type analyzerDefinition AnalyzerDefinition
+	return decodeStrict(data, (*analyzerDefinition)(a))
+}
+
+// 自定义分词器
+type TokenizerDefinition struct {
+	Type    string `json:"type"`               // 分词器类型
+	Mode    string `json:"mode,omitempty"`     // jieba: 分词模式 precise、full 或 search，默认为 precise
+	Pattern string `json:"pattern,omitempty"`  // regexp: 匹配词元的正则表达式
+	MinGram int    `json:"min_gram,omitempty"` // ngram: 最小长度，默认为1
+	MaxGram int    `json:"max_gram,omitempty"` // ngram: 最大长度，默认为2
+}
+
+func (t *TokenizerDefinition) UnmarshalJSON(data []byte) error {
+	type tokenizerDefinition TokenizerDefinition
+	return decodeStrict(data, (*tokenizerDefinition)(t))
+}
+
+// 自定义词元过滤器
+type TokenFilterDefinition struct {
+	Type      string   `json:"type"`                 // 词元过滤器类型
+	StopWords []string `json:"stop_words,omitempty"` // stop: 停用词
+	Language  string   `json:"language,omitempty"`   // stemmer: 语言，如 en、de、fr，或 porter
+	MinGram   int      `json:"min_gram,omitempty"`   // edge_ngram: 最小长度，默认为1
+	MaxGram   int      `json:"max_gram,omitempty"`   // edge_ngram: 最大长度，默认为2
+	Min       int      `json:"min,omitempty"`        // length: 最小字符数
+	Max       int      `json:"max,omitempty"`        // length: 最大字符数
+	Synonyms  []string `json:"synonyms,omitempty"`   // synonym: 每项为一组逗号分隔的同义词，如 "手机, 移动电话"
+	KeepPOS   []string `json:"keep_pos,omitempty"`   // pos: 只保留这些词性的词元，按前缀匹配，如 n、v、eng
+	DropPOS   []string `json:"drop_pos,omitempty"`   // pos: 去除这些词性的词元，如 u、x
+	Outputs   []string `json:"outputs,omitempty"`    // pinyin: 输出的词元 original、full、initials，默认全部输出
+}
+
+func (f *TokenFilterDefinition) UnmarshalJSON(data []byte) error {
+	type tokenFilterDefinition TokenFilterDefinition
+	return decodeStrict(data, (*tokenFilterDefinition)(f))
+}
+
+// 分析得到的词元
+type AnalyzeToken struct {
+	Term     string `json:"term"`
+	Start    int    `json:"start"`    // 在文本中的起始字节偏移
+	End      int    `json:"end"`      // 在文本中的结束字节偏移
+	Position int    `json:"position"` // 从1开始的词序号，位置相同的词元可以互相替代
+	Type     string `json:"type"`
+}
+
+// 写入文档时从文本字段提取关键词，保存到 keyword 类型的字段中
+type KeywordExtraction struct {
+	Field       string `json:"field"`                  // 提取关键词的文本字段
+	TargetField string `json:"target_field,omitempty"` // 保存关键词的字段，默认为 _keywords
+	TopK        int    `json:"top_k,omitempty"`        // 提取的关键词数量，默认为5
+}
+
+func (k *KeywordExtraction) UnmarshalJSON(data []byte) error {
+	type keywordExtraction KeywordExtraction
+	return decodeStrict(data, (*keywordExtraction)(k))
+}
+
+// 关键词及其 TF-IDF 权重
+type Keyword struct {
+	Word   string  `json:"word"`
+	Weight float64 `json:"weight"`
+}
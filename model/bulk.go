@@ -0,0 +1,33 @@
+package model
+
+// 批量操作类型
+const (
+	BulkActionIndex  = "index"
+	BulkActionUpdate = "update"
+	BulkActionDelete = "delete"
+)
+
+// 批量操作的元数据行，如 {"index": {"index_name": "products", "id": "1"}}
+type BulkActionMeta struct {
+	IndexName string  `json:"index_name"`
+	ID        string  `json:"id"`
+	IfVersion *uint64 `json:"if_version,omitempty"` // 指定时仅在文档当前版本一致时执行
+}
+
+// 单个批量操作的执行结果
+type BulkItemResult struct {
+	Action    string `json:"action"`
+	IndexName string `json:"index_name,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Version   uint64 `json:"_version,omitempty"`
+	SeqNo     uint64 `json:"_seq_no,omitempty"`
+	Status    int    `json:"status"` // 与HTTP状态码含义一致
+	Error     string `json:"error,omitempty"`
+}
+
+// 批量操作响应
+type BulkResponse struct {
+	Took   int64            `json:"took"`   // 耗时(毫秒)
+	Errors bool             `json:"errors"` // 是否存在失败的操作
+	Items  []BulkItemResult `json:"items"`
+}
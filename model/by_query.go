@@ -0,0 +1,23 @@
+package model
+
+// 按查询删除/更新的选项
+type ByQueryOptions struct {
+	BatchSize int  // 每批处理的文档数量
+	DryRun    bool // 只统计匹配的文档数量，不做修改
+}
+
+// 按查询删除/更新中失败的文档
+type ByQueryFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// 按查询删除/更新的结果
+type ByQueryResult struct {
+	Took     int64            `json:"took"`    // 耗时(毫秒)
+	DryRun   bool             `json:"dry_run"` // 是否为试运行
+	Matched  uint64           `json:"matched"` // 匹配的文档数量
+	Changed  uint64           `json:"changed"` // 删除或更新成功的文档数量
+	Failed   uint64           `json:"failed"`  // 处理失败的文档数量
+	Failures []ByQueryFailure `json:"failures,omitempty"`
+}
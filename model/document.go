@@ -2,6 +2,22 @@ package model
 
 // Document 定义搜索文档结构
 type Document struct {
-	ID     string                 `json:"id"`
-	Fields map[string]interface{} `json:"fields"`
+	ID      string                 `json:"id"`
+	Fields  map[string]interface{} `json:"fields"`
+	Version uint64                 `json:"_version,omitempty"` // 文档版本，每次写入加一
+	SeqNo   uint64                 `json:"_seq_no,omitempty"`  // 索引内单调递增的写入序列号
+}
+
+// 文档更新模式
+const (
+	UpdateModeReplace = "replace" // 整体覆盖
+	UpdateModeMerge   = "merge"   // 深度合并到已存在的文档
+	UpdateModeUpsert  = "upsert"  // 文档存在时合并，否则新建
+)
+
+// 文档写入结果
+type WriteResult struct {
+	ID      string `json:"id"`
+	Version uint64 `json:"_version"`
+	SeqNo   uint64 `json:"_seq_no"`
 }
@@ -0,0 +1,18 @@
+package model
+
+// 文档定位信息
+type DocumentRef struct {
+	IndexName string `json:"index_name"`
+	ID        string `json:"id"`
+}
+
+// 批量获取文档的单个结果
+type GetResult struct {
+	IndexName string                 `json:"index_name"`
+	ID        string                 `json:"id"`
+	Found     bool                   `json:"found"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Version   uint64                 `json:"_version,omitempty"`
+	SeqNo     uint64                 `json:"_seq_no,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+// 索引状态
+const (
+	IndexStatusOpen   = "open"
+	IndexStatusClosed = "closed"
+)
+
+// 索引存储方式
+const (
+	StorageDisk   = "disk"   // 持久化到数据目录
+	StorageMemory = "memory" // 只保存在内存中，关闭服务后数据丢失
+)
+
+// 创建索引的选项
+type IndexOptions struct {
+	Storage          string              // disk / memory，为空时使用 Engine 的默认存储方式
+	Dynamic          string              // 未定义字段的处理方式: true / false / strict，为空时为 true
+	DynamicTemplates []DynamicTemplate   // 动态模板，为未定义的字段选择映射
+	Analysis         *Analysis           // 自定义分析器，字段可以通过 analyzer 引用
+	Keywords         []KeywordExtraction // 写入文档时提取关键词
+}
+
+// 索引信息
+type IndexInfo struct {
+	Name     string      `json:"name"`
+	Status   string      `json:"status"`            // open / closed
+	Storage  string      `json:"storage"`           // disk / memory
+	DocCount uint64      `json:"doc_count"`         // 已关闭的索引为0
+	Mapping  interface{} `json:"mapping,omitempty"` // 索引映射，仅在查询单个索引时返回
+
+	// 动态映射设置，仅在查询单个打开的索引时返回
+	Dynamic          string            `json:"dynamic,omitempty"`
+	DynamicTemplates []DynamicTemplate `json:"dynamic_templates,omitempty"`
+
+	// 关键词提取设置，仅在查询单个打开的索引时返回
+	Keywords []KeywordExtraction `json:"keywords,omitempty"`
+}
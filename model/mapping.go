@@ -0,0 +1,119 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// 字段类型
+const (
+	FieldTypeText     = "text"     // 全文检索，可指定分词器
+	FieldTypeKeyword  = "keyword"  // 不分词的精确值
+	FieldTypeNumber   = "number"   // 数值
+	FieldTypeDate     = "date"     // 日期时间
+	FieldTypeBoolean  = "boolean"  // 布尔值
+	FieldTypeGeoPoint = "geopoint" // 经纬度坐标
+	FieldTypeGeoShape = "geoshape" // GeoJSON 形状
+	FieldTypeIP       = "ip"       // IPv4/IPv6 地址
+	FieldTypeVector   = "vector"   // 向量，需使用 -tags vectors 编译
+	FieldTypeObject   = "object"   // 嵌套对象，通过 properties 定义子字段
+)
+
+// 字段映射配置
+// 可以简写为字符串，如 "keyword"、"number"，或分词器名称如 "jieba"(使用该分词器的文本字段)
+// 字段名可以使用点号表示嵌套对象中的字段，如 "specs.color"
+type FieldMapping struct {
+	Type     string `json:"type"`               // 字段类型，为空时为 text
+	Analyzer string `json:"analyzer,omitempty"` // text 字段的分词器
+
+	// object 字段的子字段
+	Properties map[string]FieldMapping `json:"properties,omitempty"`
+
+	// date 字段的日期解析方式，二选一
+	DateFormat  string   `json:"date_format,omitempty"`  // 已注册的日期解析器名称，如 dateTimeOptional
+	DateLayouts []string `json:"date_layouts,omitempty"` // Go 时间格式，如 2006-01-02，按顺序尝试解析
+
+	// vector 字段配置
+	Dims                    int    `json:"dims,omitempty"`                       // 向量维度
+	Similarity              string `json:"similarity,omitempty"`                 // 相似度算法: cosine, dot_product, l2_norm
+	VectorIndexOptimizedFor string `json:"vector_index_optimized_for,omitempty"` // 优化目标: recall, latency, memory-efficient
+
+	// 索引选项，为空时使用字段类型的默认值
+	Store              *bool `json:"store,omitempty"`                // 是否保存原始值，用于获取文档及返回搜索结果字段
+	Index              *bool `json:"index,omitempty"`                // 是否建立索引，不建立索引的字段不能被搜索
+	IncludeInAll       *bool `json:"include_in_all,omitempty"`       // 是否加入 _all 字段
+	DocValues          *bool `json:"doc_values,omitempty"`           // 是否保存列存数据，用于排序和分面统计
+	IncludeTermVectors *bool `json:"include_term_vectors,omitempty"` // 是否保存词条位置，用于短语查询和高亮
+}
+
+func (f *FieldMapping) UnmarshalJSON(data []byte) error {
+	// 简写形式
+	var shorthand string
+	if err := json.Unmarshal(data, &shorthand); err == nil {
+		*f = FieldMapping{Type: shorthand}
+		return nil
+	}
+
+	type fieldMapping FieldMapping
+	return decodeStrict(data, (*fieldMapping)(f))
+}
+
+// 解析 JSON 对象，出现未知的键时返回错误
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// 未在映射中定义的字段的处理方式
+const (
+	DynamicTrue   = "true"   // 按动态模板或默认规则建立索引
+	DynamicFalse  = "false"  // 忽略未定义的字段，只保存在原始文档中
+	DynamicStrict = "strict" // 拒绝包含未定义字段的文档
+)
+
+// 索引的 dynamic 设置，JSON 中可以是 true、false 或 "strict"
+type Dynamic string
+
+func (d *Dynamic) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*d = Dynamic(strconv.FormatBool(b))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("dynamic 只能是 true、false 或 strict")
+	}
+	switch s {
+	case DynamicTrue, DynamicFalse, DynamicStrict:
+		*d = Dynamic(s)
+		return nil
+	default:
+		return fmt.Errorf("dynamic 只能是 true、false 或 strict: %s", s)
+	}
+}
+
+// JSON 值类型，用于动态模板的 match_mapping_type
+const (
+	JSONTypeString  = "string"
+	JSONTypeNumber  = "number"
+	JSONTypeBoolean = "boolean"
+)
+
+// 动态模板，为未定义的字段按名称或 JSON 类型指定映射
+// 按顺序使用第一个所有条件都满足的模板，只在 dynamic 为 true 时生效
+type DynamicTemplate struct {
+	Match            string       `json:"match,omitempty"`              // 字段名匹配模式，支持 * 和 ? 通配符，如 *_id
+	PathMatch        string       `json:"path_match,omitempty"`         // 完整字段路径匹配模式，如 specs.*
+	MatchMappingType string       `json:"match_mapping_type,omitempty"` // 字段值的 JSON 类型: string, number, boolean
+	Mapping          FieldMapping `json:"mapping"`                      // 匹配字段使用的映射，不能是 object 类型
+}
+
+func (t *DynamicTemplate) UnmarshalJSON(data []byte) error {
+	type dynamicTemplate DynamicTemplate
+	return decodeStrict(data, (*dynamicTemplate)(t))
+}
@@ -0,0 +1,116 @@
+package model
+
+// Query 结构化查询DSL，每个节点只应设置一种子句
+type Query struct {
+	Bool         *BoolQuery         `json:"bool,omitempty"`
+	Match        *MatchQuery        `json:"match,omitempty"`
+	MatchPhrase  *MatchPhraseQuery  `json:"match_phrase,omitempty"`
+	Term         *TermQuery         `json:"term,omitempty"`
+	Terms        *TermsQuery        `json:"terms,omitempty"`
+	Prefix       *PrefixQuery       `json:"prefix,omitempty"`
+	Wildcard     *WildcardQuery     `json:"wildcard,omitempty"`
+	Regexp       *RegexpQuery       `json:"regexp,omitempty"`
+	Fuzzy        *FuzzyQuery        `json:"fuzzy,omitempty"`
+	NumericRange *NumericRangeQuery `json:"numeric_range,omitempty"`
+	DateRange    *DateRangeQuery    `json:"date_range,omitempty"`
+	MatchAll     *MatchAllQuery     `json:"match_all,omitempty"`
+}
+
+// 布尔组合查询
+type BoolQuery struct {
+	Must          []Query  `json:"must,omitempty"`
+	Should        []Query  `json:"should,omitempty"`
+	MustNot       []Query  `json:"must_not,omitempty"`
+	Filter        []Query  `json:"filter,omitempty"` // 必须匹配，但不参与评分
+	MinimumShould float64  `json:"minimum_should_match,omitempty"`
+	Boost         *float64 `json:"boost,omitempty"`
+}
+
+// 分词匹配查询
+type MatchQuery struct {
+	Field     string   `json:"field"`
+	Query     string   `json:"query"`
+	Analyzer  string   `json:"analyzer,omitempty"`
+	Operator  string   `json:"operator,omitempty"` // or(默认) / and
+	Fuzziness int      `json:"fuzziness,omitempty"`
+	Boost     *float64 `json:"boost,omitempty"`
+}
+
+// 短语匹配查询
+type MatchPhraseQuery struct {
+	Field    string   `json:"field"`
+	Query    string   `json:"query"`
+	Analyzer string   `json:"analyzer,omitempty"`
+	Slop     int      `json:"slop,omitempty"` // 词之间允许多出的间隔词数，词的顺序必须与查询一致
+	Boost    *float64 `json:"boost,omitempty"`
+}
+
+// 精确词条查询
+type TermQuery struct {
+	Field string   `json:"field"`
+	Value string   `json:"value"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+// 多词条查询，匹配任意一个即可
+type TermsQuery struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+	Boost  *float64 `json:"boost,omitempty"`
+}
+
+// 前缀查询
+type PrefixQuery struct {
+	Field string   `json:"field"`
+	Value string   `json:"value"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+// 通配符查询，支持 * 和 ?
+type WildcardQuery struct {
+	Field string   `json:"field"`
+	Value string   `json:"value"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+// 正则表达式查询
+type RegexpQuery struct {
+	Field string   `json:"field"`
+	Value string   `json:"value"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+// 模糊查询
+type FuzzyQuery struct {
+	Field        string   `json:"field"`
+	Value        string   `json:"value"`
+	Fuzziness    int      `json:"fuzziness,omitempty"` // 默认为1
+	PrefixLength int      `json:"prefix_length,omitempty"`
+	Boost        *float64 `json:"boost,omitempty"`
+}
+
+// 数值范围查询
+type NumericRangeQuery struct {
+	Field string   `json:"field"`
+	GT    *float64 `json:"gt,omitempty"`
+	GTE   *float64 `json:"gte,omitempty"`
+	LT    *float64 `json:"lt,omitempty"`
+	LTE   *float64 `json:"lte,omitempty"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+// 日期范围查询，日期默认按 RFC3339 解析
+type DateRangeQuery struct {
+	Field          string   `json:"field"`
+	GT             string   `json:"gt,omitempty"`
+	GTE            string   `json:"gte,omitempty"`
+	LT             string   `json:"lt,omitempty"`
+	LTE            string   `json:"lte,omitempty"`
+	DateTimeParser string   `json:"datetime_parser,omitempty"`
+	Boost          *float64 `json:"boost,omitempty"`
+}
+
+// 匹配所有文档
+type MatchAllQuery struct {
+	Boost *float64 `json:"boost,omitempty"`
+}
@@ -0,0 +1,55 @@
+package model
+
+// 查询条件
+type SearchQuery struct {
+	Type  int     // 1: 查询字符串, 2: 数值范围, 3: 结构化查询DSL
+	Query string  // 查询字符串
+	Field string  // 数值范围的字段
+	Start float64 // 数值范围的起始值
+	End   float64 // 数值范围的结束值
+	DSL   *Query  // 结构化查询DSL
+}
+
+// 搜索选项：分页、排序、高亮及分面统计
+type SearchOptions struct {
+	Page      int
+	Size      int
+	SortBy    string
+	Highlight *HighlightRequest
+	Facets    map[string]FacetRequest
+}
+
+// 高亮请求参数
+type HighlightRequest struct {
+	Fields            []string `json:"fields,omitempty"`              // 需要高亮的字段，为空时高亮所有命中字段
+	FragmentSize      int      `json:"fragment_size,omitempty"`       // 片段长度(字符数)，默认100
+	NumberOfFragments int      `json:"number_of_fragments,omitempty"` // 每个字段返回的片段数，默认3
+	PreTag            string   `json:"pre_tag,omitempty"`             // 默认<mark>
+	PostTag           string   `json:"post_tag,omitempty"`            // 默认</mark>
+}
+
+// 分面统计请求参数
+type FacetRequest struct {
+	Type          string               `json:"type"`                     // terms / numeric_range / date_range
+	Field         string               `json:"field"`                    // 统计字段
+	Size          int                  `json:"size,omitempty"`           // terms 返回的词条数量，默认10
+	NumericRanges []NumericRangeBucket `json:"numeric_ranges,omitempty"` // numeric_range 的区间
+	DateRanges    []DateRangeBucket    `json:"date_ranges,omitempty"`    // date_range 的区间
+	Interval      string               `json:"interval,omitempty"`       // date_range 按 hour/day/week/month/year 自动生成区间
+	Start         string               `json:"start,omitempty"`          // 自动生成区间的起始时间 (RFC3339)
+	End           string               `json:"end,omitempty"`            // 自动生成区间的结束时间 (RFC3339)
+}
+
+// 数值区间，包含 min，不包含 max
+type NumericRangeBucket struct {
+	Name string   `json:"name"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+}
+
+// 日期区间，包含 start，不包含 end
+type DateRangeBucket struct {
+	Name  string `json:"name"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
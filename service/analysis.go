@@ -0,0 +1,363 @@
+package service
+
+import (
+	"fmt"
+	"go-search/analysis/jieba"
+	"go-search/analysis/ngram"
+	"go-search/analysis/pinyin"
+	"go-search/analysis/synonym"
+	"go-search/analysis/zhconv"
+	"go-search/model"
+	"sort"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/analysis/lang/da"
+	"github.com/blevesearch/bleve/v2/analysis/lang/de"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/lang/es"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fi"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fr"
+	"github.com/blevesearch/bleve/v2/analysis/lang/it"
+	"github.com/blevesearch/bleve/v2/analysis/lang/nl"
+	"github.com/blevesearch/bleve/v2/analysis/lang/no"
+	"github.com/blevesearch/bleve/v2/analysis/lang/pt"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ru"
+	"github.com/blevesearch/bleve/v2/analysis/lang/sv"
+	"github.com/blevesearch/bleve/v2/analysis/token/edgengram"
+	"github.com/blevesearch/bleve/v2/analysis/token/length"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/porter"
+	"github.com/blevesearch/bleve/v2/analysis/token/stop"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/regexp"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/whitespace"
+	"github.com/blevesearch/bleve/v2/analysis/tokenmap"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// 分词器类型对应的 bleve 注册名称
+var tokenizerTypes = map[string]string{
+	model.TokenizerJieba:      jieba.TokenizerName,
+	model.TokenizerUnicode:    unicode.Name,
+	model.TokenizerWhitespace: whitespace.Name,
+	model.TokenizerNgram:      ngram.TokenizerName,
+	model.TokenizerRegexp:     regexp.Name,
+}
+
+// 词元过滤器类型对应的 bleve 注册名称，stemmer 按语言选择
+var tokenFilterTypes = map[string]string{
+	model.TokenFilterLowercase: lowercase.Name,
+	model.TokenFilterCJKWidth:  cjk.WidthName,
+	model.TokenFilterStop:      stop.Name,
+	model.TokenFilterStemmer:   "",
+	model.TokenFilterEdgeNgram: edgengram.Name,
+	model.TokenFilterLength:    length.Name,
+	model.TokenFilterSynonym:   synonym.Name,
+	model.TokenFilterPOS:       jieba.POSFilterName,
+	model.TokenFilterPinyin:    pinyin.Name,
+}
+
+// 字符过滤器类型对应的 bleve 注册名称，字符过滤器都没有参数，只能直接使用
+var charFilterTypes = map[string]string{
+	model.CharFilterT2S: zhconv.T2SName,
+	model.CharFilterS2T: zhconv.S2TName,
+}
+
+// 不需要参数、可以在分析器中直接使用的类型
+var (
+	inlineCharFilters  = []string{model.CharFilterT2S, model.CharFilterS2T}
+	inlineTokenizers   = []string{model.TokenizerJieba, model.TokenizerUnicode, model.TokenizerWhitespace, model.TokenizerNgram}
+	inlineTokenFilters = []string{model.TokenFilterLowercase, model.TokenFilterCJKWidth, model.TokenFilterPinyin}
+)
+
+// stemmer 支持的语言及对应的 bleve 词元过滤器
+var stemmers = map[string]string{
+	"porter": porter.Name,
+	"en":     en.SnowballStemmerName,
+	"de":     de.SnowballStemmerName,
+	"fr":     fr.SnowballStemmerName,
+	"es":     es.SnowballStemmerName,
+	"it":     it.SnowballStemmerName,
+	"pt":     pt.LightStemmerName,
+	"nl":     nl.SnowballStemmerName,
+	"ru":     ru.SnowballStemmerName,
+	"sv":     sv.SnowballStemmerName,
+	"da":     da.SnowballStemmerName,
+	"fi":     fi.SnowballStemmerName,
+	"no":     no.SnowballStemmerName,
+}
+
+// 将自定义分析配置注册到索引映射中，需在构建字段映射之前调用
+func (e *Engine) addAnalysis(indexMapping *mapping.IndexMappingImpl, analysis *model.Analysis) error {
+	if analysis == nil {
+		return nil
+	}
+
+	for _, name := range sortedKeys(analysis.Tokenizers) {
+		if _, conflict := tokenizerTypes[name]; conflict {
+			return fmt.Errorf("分词器名称 %s 与内置类型重名", name)
+		}
+		config, err := tokenizerConfig(analysis.Tokenizers[name])
+		if err == nil {
+			err = indexMapping.AddCustomTokenizer(name, config)
+		}
+		if err != nil {
+			return fmt.Errorf("分词器 %s 配置不合法: %v", name, err)
+		}
+	}
+
+	for _, name := range sortedKeys(analysis.TokenFilters) {
+		if _, conflict := tokenFilterTypes[name]; conflict {
+			return fmt.Errorf("词元过滤器名称 %s 与内置类型重名", name)
+		}
+		config, err := tokenFilterConfig(indexMapping, name, analysis.TokenFilters[name])
+		if err == nil {
+			err = indexMapping.AddCustomTokenFilter(name, config)
+		}
+		if err != nil {
+			return fmt.Errorf("词元过滤器 %s 配置不合法: %v", name, err)
+		}
+	}
+
+	for _, name := range sortedKeys(analysis.Analyzers) {
+		if _, conflict := e.analyzers[name]; conflict {
+			return fmt.Errorf("分析器名称 %s 与已注册的分词器重名", name)
+		}
+		definition := analysis.Analyzers[name]
+		charFilters := make([]interface{}, 0, len(definition.CharFilters))
+		for _, filter := range definition.CharFilters {
+			resolved, err := resolveComponent(filter, map[string]struct{}(nil), inlineCharFilters, charFilterTypes)
+			if err != nil {
+				return fmt.Errorf("分析器 %s 的字符过滤器不合法: %v", name, err)
+			}
+			charFilters = append(charFilters, resolved)
+		}
+		tokenizer, err := resolveComponent(definition.Tokenizer, analysis.Tokenizers, inlineTokenizers, tokenizerTypes)
+		if err != nil {
+			return fmt.Errorf("分析器 %s 的分词器不合法: %v", name, err)
+		}
+		filters := make([]interface{}, 0, len(definition.TokenFilters))
+		for _, filter := range definition.TokenFilters {
+			resolved, err := resolveComponent(filter, analysis.TokenFilters, inlineTokenFilters, tokenFilterTypes)
+			if err != nil {
+				return fmt.Errorf("分析器 %s 的词元过滤器不合法: %v", name, err)
+			}
+			filters = append(filters, resolved)
+		}
+		err = indexMapping.AddCustomAnalyzer(name, map[string]interface{}{
+			"type":          custom.Name,
+			"char_filters":  charFilters,
+			"tokenizer":     tokenizer,
+			"token_filters": filters,
+		})
+		if err != nil {
+			return fmt.Errorf("分析器 %s 配置不合法: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// 分析器引用的组件名称，优先使用自定义的组件，其次是可以直接使用的内置类型
+func resolveComponent[T any](name string, defined map[string]T, inline []string, types map[string]string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("名称不能为空")
+	}
+	if _, ok := defined[name]; ok {
+		return name, nil
+	}
+	for _, typ := range inline {
+		if typ == name {
+			return types[typ], nil
+		}
+	}
+	return "", fmt.Errorf("%s 未定义", name)
+}
+
+// 自定义分词器对应的 bleve 配置，数值和列表使用 JSON 解析后的类型，与索引重新打开时一致
+func tokenizerConfig(definition model.TokenizerDefinition) (map[string]interface{}, error) {
+	typ, ok := tokenizerTypes[definition.Type]
+	if !ok {
+		return nil, fmt.Errorf("不支持的分词器类型: %q", definition.Type)
+	}
+	config := map[string]interface{}{"type": typ}
+
+	switch definition.Type {
+	case model.TokenizerJieba:
+		if definition.Mode != "" {
+			if _, err := jieba.ParseMode(definition.Mode); err != nil {
+				return nil, err
+			}
+			config["mode"] = definition.Mode
+		}
+	case model.TokenizerRegexp:
+		if definition.Pattern == "" {
+			return nil, fmt.Errorf("regexp 分词器必须指定 pattern")
+		}
+		config["regexp"] = definition.Pattern
+	case model.TokenizerNgram:
+		minGram, maxGram, err := gramRange(definition.MinGram, definition.MaxGram)
+		if err != nil {
+			return nil, err
+		}
+		config["min"], config["max"] = minGram, maxGram
+	}
+	return config, nil
+}
+
+// 自定义词元过滤器对应的 bleve 配置
+func tokenFilterConfig(indexMapping *mapping.IndexMappingImpl, name string, definition model.TokenFilterDefinition) (map[string]interface{}, error) {
+	typ, ok := tokenFilterTypes[definition.Type]
+	if !ok {
+		return nil, fmt.Errorf("不支持的词元过滤器类型: %q", definition.Type)
+	}
+	config := map[string]interface{}{"type": typ}
+
+	switch definition.Type {
+	case model.TokenFilterStop:
+		if len(definition.StopWords) == 0 {
+			return nil, fmt.Errorf("stop 过滤器必须指定 stop_words")
+		}
+		// 停用词注册为以过滤器名称命名的词表
+		tokenMap := "stop_words_" + name
+		err := indexMapping.AddCustomTokenMap(tokenMap, map[string]interface{}{
+			"type":   tokenmap.Name,
+			"tokens": stringList(definition.StopWords),
+		})
+		if err != nil {
+			return nil, err
+		}
+		config["stop_token_map"] = tokenMap
+	case model.TokenFilterStemmer:
+		stemmer, ok := stemmers[definition.Language]
+		if !ok {
+			return nil, fmt.Errorf("stemmer 不支持的语言: %q", definition.Language)
+		}
+		config["type"] = stemmer
+	case model.TokenFilterEdgeNgram:
+		minGram, maxGram, err := gramRange(definition.MinGram, definition.MaxGram)
+		if err != nil {
+			return nil, err
+		}
+		config["min"], config["max"] = minGram, maxGram
+	case model.TokenFilterLength:
+		if definition.Min <= 0 && definition.Max <= 0 {
+			return nil, fmt.Errorf("length 过滤器必须指定 min 或 max")
+		}
+		config["min"], config["max"] = float64(definition.Min), float64(definition.Max)
+	case model.TokenFilterSynonym:
+		if len(definition.Synonyms) == 0 {
+			return nil, fmt.Errorf("synonym 过滤器必须指定 synonyms")
+		}
+		config["synonyms"] = stringList(definition.Synonyms)
+	case model.TokenFilterPOS:
+		if (len(definition.KeepPOS) == 0) == (len(definition.DropPOS) == 0) {
+			return nil, fmt.Errorf("pos 过滤器必须指定 keep_pos 或 drop_pos 其中一个")
+		}
+		config["keep"], config["drop"] = stringList(definition.KeepPOS), stringList(definition.DropPOS)
+	case model.TokenFilterPinyin:
+		if _, err := pinyin.NewPinyinFilter(definition.Outputs...); err != nil {
+			return nil, err
+		}
+		config["outputs"] = stringList(definition.Outputs)
+	}
+	return config, nil
+}
+
+// n-gram 长度范围，未指定时使用默认值
+func gramRange(minGram, maxGram int) (float64, float64, error) {
+	if minGram == 0 {
+		minGram = ngram.DefaultMinGram
+	}
+	if maxGram == 0 {
+		maxGram = max(minGram, ngram.DefaultMaxGram)
+	}
+	if minGram < 1 || maxGram < minGram {
+		return 0, 0, fmt.Errorf("min_gram 和 max_gram 不合法: %d, %d", minGram, maxGram)
+	}
+	return float64(minGram), float64(maxGram), nil
+}
+
+func stringList(values []string) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 重新加载 jieba 分词器的用户词典，返回重新加载的分词实例数量
+// 词典对所有索引生效，已建立索引的文档需要重建索引后才会按新的词典分词
+func (e *Engine) ReloadJiebaUserDict() (int, error) {
+	return jieba.ReloadUserDict()
+}
+
+// 词元类型名称
+var tokenTypes = map[analysis.TokenType]string{
+	analysis.AlphaNumeric: "alphanumeric",
+	analysis.Ideographic:  "ideographic",
+	analysis.Numeric:      "numeric",
+	analysis.DateTime:     "datetime",
+	analysis.Shingle:      "shingle",
+	analysis.Single:       "single",
+	analysis.Double:       "double",
+	analysis.Boolean:      "boolean",
+}
+
+// 使用分析器处理文本，返回得到的词元
+// 指定索引时使用字段的分析器或索引中定义的分析器，否则使用注册的分析器；同时指定字段和分析器时以分析器为准
+func (e *Engine) Analyze(indexName, field, analyzerName, text string) ([]model.AnalyzeToken, error) {
+	var indexMapping mapping.IndexMapping
+	if indexName == "" {
+		if field != "" {
+			return nil, invalidRequest("指定字段时必须指定索引")
+		}
+		indexMapping = bleve.NewIndexMapping()
+	} else {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+		index, exists := e.indexes[indexName]
+		if !exists {
+			return nil, indexNotFound(indexName)
+		}
+		indexMapping = index.Mapping()
+	}
+
+	switch {
+	case analyzerName != "":
+		analyzerName = e.analyzerName(analyzerName)
+	case field != "":
+		analyzerName = indexMapping.AnalyzerNameForPath(field)
+	default:
+		return nil, invalidRequest("必须指定字段或分析器")
+	}
+	analyzer := indexMapping.AnalyzerNamed(analyzerName)
+	if analyzer == nil {
+		return nil, invalidRequest("分析器 %s 不存在", analyzerName)
+	}
+
+	stream := analyzer.Analyze([]byte(text))
+	tokens := make([]model.AnalyzeToken, 0, len(stream))
+	for _, token := range stream {
+		tokens = append(tokens, model.AnalyzeToken{
+			Term:     string(token.Term),
+			Start:    token.Start,
+			End:      token.End,
+			Position: token.Position,
+			Type:     tokenTypes[token.Type],
+		})
+	}
+	return tokens, nil
+}
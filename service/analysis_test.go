@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/json"
+	"go-search/model"
+	"testing"
+)
+
+func TestCustomAnalysis(t *testing.T) {
+	var opts struct {
+		Fields   map[string]model.FieldMapping `json:"fields"`
+		Analysis *model.Analysis               `json:"analysis"`
+	}
+	err := json.Unmarshal([]byte(`{
+		"fields": {
+			"title": {"type": "text", "analyzer": "zh_synonym"},
+			"sku": "sku_ngram",
+			"body": {"type": "text", "analyzer": "en_stem"},
+			"place": "zh_search",
+			"summary": "zh_nouns",
+			"brand": "zh_pinyin",
+			"shop": "zh_initials",
+			"seller": "zh_t2s",
+			"origin": "jieba_t2s"
+		},
+		"analysis": {
+			"analyzers": {
+				"zh_synonym": {"tokenizer": "jieba", "token_filters": ["cjk_width", "lowercase", "zh_stop", "phone_synonym"]},
+				"sku_ngram": {"tokenizer": "sku_grams", "token_filters": ["lowercase"]},
+				"en_stem": {"tokenizer": "unicode", "token_filters": ["lowercase", "english", "short"]},
+				"zh_search": {"tokenizer": "jieba_search"},
+				"zh_nouns": {"tokenizer": "jieba", "token_filters": ["nouns"]},
+				"zh_pinyin": {"tokenizer": "jieba", "token_filters": ["lowercase", "pinyin"]},
+				"zh_initials": {"tokenizer": "jieba", "token_filters": ["initials"]},
+				"zh_t2s": {"char_filters": ["t2s"], "tokenizer": "jieba", "token_filters": ["lowercase"]}
+			},
+			"tokenizers": {
+				"sku_grams": {"type": "ngram", "min_gram": 3, "max_gram": 3},
+				"jieba_search": {"type": "jieba", "mode": "search"}
+			},
+			"token_filters": {
+				"zh_stop": {"type": "stop", "stop_words": ["的"]},
+				"phone_synonym": {"type": "synonym", "synonyms": ["手机, 移动电话"]},
+				"english": {"type": "stemmer", "language": "en"},
+				"short": {"type": "length", "min": 3},
+				"nouns": {"type": "pos", "keep_pos": ["n", "eng"]},
+				"initials": {"type": "pinyin", "outputs": ["initials"]}
+			}
+		}
+	}`), &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 自定义分析配置保存在索引映射中，重新打开索引后仍然有效
+	e := NewEngine(WithDataDir(t.TempDir()))
+	defer e.CloseAll()
+	if err := e.InitIndex("analysis_test", opts.Fields, model.IndexOptions{Analysis: opts.Analysis}); err != nil {
+		t.Fatal(err)
+	}
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{
+		"title":   "小米的ＮＦＣ手机",
+		"sku":     "XM-14PRO",
+		"body":    "running shoes on sale",
+		"place":   "南京市长江大桥",
+		"summary": "小米的NFC手机",
+		"brand":   "苹果手机",
+		"shop":    "招商银行",
+		"seller":  "蘋果手機專賣店",
+		"origin":  "臺灣製造",
+	}}
+	if _, err := e.AddDocument("analysis_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	match := func(field, query string) *model.Query {
+		return &model.Query{Match: &model.MatchQuery{Field: field, Query: query}}
+	}
+	tests := []struct {
+		name string
+		q    *model.Query
+		want uint64
+	}{
+		{"synonym", match("title", "移动电话"), 1},
+		{"width and lowercase", &model.Query{Term: &model.TermQuery{Field: "title", Value: "n"}}, 1},
+		{"stop word", &model.Query{Term: &model.TermQuery{Field: "title", Value: "的"}}, 0},
+		{"ngram", match("sku", "14p"), 1},
+		{"stemmer", match("body", "runs"), 1},
+		{"length", &model.Query{Term: &model.TermQuery{Field: "body", Value: "on"}}, 0},
+		{"jieba search mode", &model.Query{Term: &model.TermQuery{Field: "place", Value: "大桥"}}, 1},
+		{"pos keep", &model.Query{Term: &model.TermQuery{Field: "summary", Value: "手机"}}, 1},
+		{"pos drop", &model.Query{Term: &model.TermQuery{Field: "summary", Value: "的"}}, 0},
+		{"pinyin initials", match("brand", "pg"), 1},
+		{"pinyin full", match("brand", "PingGuo ShouJi"), 1},
+		{"pinyin original", match("brand", "苹果"), 1},
+		{"pinyin initials only", match("shop", "zsyh"), 1},
+		{"pinyin without original", &model.Query{Term: &model.TermQuery{Field: "shop", Value: "银行"}}, 0},
+		{"t2s simplified query", match("seller", "苹果"), 1},
+		{"t2s traditional query", match("seller", "蘋果手機"), 1},
+		{"t2s converted term", &model.Query{Term: &model.TermQuery{Field: "seller", Value: "手机"}}, 1},
+		{"jieba t2s analyzer", match("origin", "台湾"), 1},
+	}
+	check := func() {
+		t.Helper()
+		for _, tt := range tests {
+			if n := countMatches(t, e, "analysis_test", tt.q); n != tt.want {
+				t.Errorf("%s: total = %d, want %d", tt.name, n, tt.want)
+			}
+		}
+	}
+	check()
+
+	if err := e.CloseIndex("analysis_test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.OpenIndex("analysis_test"); err != nil {
+		t.Fatal(err)
+	}
+	check()
+}
+
+func TestCustomAnalysisInvalid(t *testing.T) {
+	e := newTestEngine(t)
+
+	tests := map[string]string{
+		"unknown tokenizer":       `{"analyzers": {"a": {"tokenizer": "missing"}}}`,
+		"unknown filter":          `{"analyzers": {"a": {"tokenizer": "jieba", "token_filters": ["missing"]}}}`,
+		"filter needs definition": `{"analyzers": {"a": {"tokenizer": "jieba", "token_filters": ["stop"]}}}`,
+		"unknown tokenizer type":  `{"tokenizers": {"t": {"type": "letters"}}}`,
+		"regexp without pattern":  `{"tokenizers": {"t": {"type": "regexp"}}}`,
+		"bad jieba mode":          `{"tokenizers": {"t": {"type": "jieba", "mode": "fast"}}}`,
+		"bad regexp":              `{"tokenizers": {"t": {"type": "regexp", "pattern": "("}}}`,
+		"bad ngram range":         `{"tokenizers": {"t": {"type": "ngram", "min_gram": 3, "max_gram": 2}}}`,
+		"builtin name":            `{"tokenizers": {"unicode": {"type": "whitespace"}}}`,
+		"stop without words":      `{"token_filters": {"f": {"type": "stop"}}}`,
+		"unknown stemmer":         `{"token_filters": {"f": {"type": "stemmer", "language": "zh"}}}`,
+		"pos without classes":     `{"token_filters": {"f": {"type": "pos"}}}`,
+		"pinyin original only":    `{"token_filters": {"f": {"type": "pinyin", "outputs": ["original"]}}}`,
+		"length without range":    `{"token_filters": {"f": {"type": "length"}}}`,
+		"unknown char filter":     `{"analyzers": {"a": {"char_filters": ["t2hk"], "tokenizer": "jieba"}}}`,
+		"engine analyzer name":    `{"analyzers": {"jieba": {"tokenizer": "unicode"}}}`,
+	}
+	for name, input := range tests {
+		var analysis model.Analysis
+		if err := json.Unmarshal([]byte(input), &analysis); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := e.InitIndex("invalid_test", nil, model.IndexOptions{Analysis: &analysis}); err == nil {
+			t.Errorf("%s: InitIndex succeeded", name)
+		}
+	}
+
+	var analysis model.Analysis
+	if err := json.Unmarshal([]byte(`{"tokenizers": {"t": {"type": "ngram", "size": 2}}}`), &analysis); err == nil {
+		t.Error("unknown option accepted")
+	}
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-search/model"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// 默认每批提交的操作数量
+	DefaultBulkBatchSize = 1000
+	// 单行NDJSON的最大长度
+	maxBulkLineSize = 16 * 1024 * 1024
+)
+
+// 待提交的批量操作
+type bulkOp struct {
+	item      int // 在结果列表中的位置
+	action    string
+	id        string
+	fields    map[string]interface{}
+	ifVersion *uint64
+}
+
+// 批量执行NDJSON格式的操作，每个操作由一行元数据和(index/update时)一行文档组成
+// 操作按索引分组，每满 batchSize 个(不大于0时使用配置的默认值)提交一次，单个操作失败不影响其他操作
+// 元数据行无法解析或读取请求失败时中止请求，尚未提交的操作不再执行，已提交的操作不会回滚，返回其结果及错误
+func (e *Engine) Bulk(r io.Reader, defaultIndex string, batchSize int) (*model.BulkResponse, error) {
+	if batchSize <= 0 {
+		batchSize = e.bulkBatchSize
+	}
+
+	start := time.Now()
+	resp := &model.BulkResponse{Items: []model.BulkItemResult{}}
+	pending := make(map[string][]bulkOp)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBulkLineSize)
+
+	// 读取下一个非空行
+	lineNo := 0
+	nextLine := func() ([]byte, bool) {
+		for scanner.Scan() {
+			lineNo++
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) > 0 {
+				return line, true
+			}
+		}
+		return nil, false
+	}
+
+	var abortErr error
+	for {
+		line, ok := nextLine()
+		if !ok {
+			break
+		}
+
+		// 无法确定解析失败的操作是否带有文档行，后续内容无法继续按行对应，只能中止
+		action, meta, err := parseBulkAction(line)
+		if err != nil {
+			abortErr = fmt.Errorf("第 %d 行%v", lineNo, err)
+			break
+		}
+
+		item := len(resp.Items)
+		resp.Items = append(resp.Items, model.BulkItemResult{
+			Action:    action,
+			IndexName: meta.IndexName,
+			ID:        meta.ID,
+		})
+		result := &resp.Items[item]
+		if result.IndexName == "" {
+			result.IndexName = defaultIndex
+		}
+
+		// 除delete外的操作下一行是文档内容，不支持的操作类型同样跳过其文档行，避免被当作下一个操作解析
+		var fields map[string]interface{}
+		if action != model.BulkActionDelete {
+			source, ok := nextLine()
+			if !ok {
+				if scanner.Err() != nil {
+					resp.Items = resp.Items[:item]
+					break
+				}
+				result.Status, result.Error = http.StatusBadRequest, "缺少文档内容"
+				break
+			}
+			if jsonErr := json.Unmarshal(source, &fields); jsonErr != nil {
+				err = fmt.Errorf("文档内容不合法: %v", jsonErr)
+			}
+		}
+
+		switch {
+		case !isBulkAction(action):
+			result.Status, result.Error = http.StatusBadRequest, fmt.Sprintf("不支持的操作类型: %s", action)
+			continue
+		case err != nil:
+			result.Status, result.Error = http.StatusBadRequest, err.Error()
+			continue
+		case result.IndexName == "":
+			result.Status, result.Error = http.StatusBadRequest, "缺少索引名称"
+			continue
+		case result.ID == "":
+			result.Status, result.Error = http.StatusBadRequest, "缺少文档ID"
+			continue
+		}
+		if fields != nil {
+			if err := e.prepareFields(result.IndexName, fields); err != nil {
+				result.Status, result.Error = http.StatusBadRequest, err.Error()
+				if !errors.Is(err, ErrInvalid) {
+					result.Status = http.StatusInternalServerError
+				}
+				continue
+			}
+		}
+
+		ops := append(pending[result.IndexName], bulkOp{
+			item:      item,
+			action:    action,
+			id:        result.ID,
+			fields:    fields,
+			ifVersion: meta.IfVersion,
+		})
+		if len(ops) >= batchSize {
+			e.flushBulk(result.IndexName, ops, resp.Items)
+			ops = ops[:0]
+		}
+		pending[result.IndexName] = ops
+	}
+
+	if err := scanner.Err(); err != nil && abortErr == nil {
+		abortErr = fmt.Errorf("读取批量请求失败: %v", err)
+	}
+	if abortErr != nil {
+		// 中止时只返回已执行的操作的结果
+		resp.Items = withoutPending(resp.Items, pending)
+		resp.Errors = true
+		resp.Took = time.Since(start).Milliseconds()
+		return resp, abortErr
+	}
+
+	for indexName, ops := range pending {
+		if len(ops) > 0 {
+			e.flushBulk(indexName, ops, resp.Items)
+		}
+	}
+
+	for _, item := range resp.Items {
+		if item.Error != "" {
+			resp.Errors = true
+			break
+		}
+	}
+	resp.Took = time.Since(start).Milliseconds()
+	return resp, nil
+}
+
+// 去掉尚未提交的操作的结果
+func withoutPending(items []model.BulkItemResult, pending map[string][]bulkOp) []model.BulkItemResult {
+	skip := make(map[int]struct{})
+	for _, ops := range pending {
+		for _, op := range ops {
+			skip[op.item] = struct{}{}
+		}
+	}
+	result := make([]model.BulkItemResult, 0, len(items)-len(skip))
+	for i, item := range items {
+		if _, ok := skip[i]; !ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func isBulkAction(action string) bool {
+	switch action {
+	case model.BulkActionIndex, model.BulkActionUpdate, model.BulkActionDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// 解析元数据行，返回操作类型和元数据，操作类型是否支持由调用方检查
+func parseBulkAction(line []byte) (string, model.BulkActionMeta, error) {
+	var raw map[string]model.BulkActionMeta
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return "", model.BulkActionMeta{}, fmt.Errorf("操作元数据不合法: %v", err)
+	}
+	if len(raw) != 1 {
+		return "", model.BulkActionMeta{}, errors.New("只能包含一个操作")
+	}
+
+	for action, meta := range raw {
+		return action, meta, nil
+	}
+	return "", model.BulkActionMeta{}, nil
+}
+
+// 将一组操作作为一个batch提交到索引，并记录每个操作的结果
+func (e *Engine) flushBulk(indexName string, ops []bulkOp, items []model.BulkItemResult) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	index, exists := e.indexes[indexName]
+	if !exists {
+		err := indexNotFound(indexName)
+		for _, op := range ops {
+			items[op.item].Status, items[op.item].Error = http.StatusNotFound, err.Error()
+		}
+		return
+	}
+
+	writer := e.writers[indexName]
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	batch := index.NewBatch()
+	added := make([]bulkOp, 0, len(ops))
+	// 本批次内最新的文档内容，使同一文档的后续操作基于未提交的修改进行，nil表示已删除
+	docs := make(map[string]*model.Document)
+	// 本批次内删除的文档最后的版本号
+	deleted := make(map[string]uint64)
+	for _, op := range ops {
+		item := &items[op.item]
+
+		current, found := docs[op.id]
+		if !found {
+			var err error
+			current, err = loadDocument(index, op.id)
+			if err != nil {
+				item.Status, item.Error = http.StatusInternalServerError, err.Error()
+				continue
+			}
+		}
+		if err := checkVersion(current, op.id, op.ifVersion); err != nil {
+			item.Status, item.Error = http.StatusConflict, err.Error()
+			continue
+		}
+
+		if op.action == model.BulkActionDelete {
+			if current == nil {
+				item.Status, item.Error = http.StatusNotFound, documentNotFound(indexName, op.id).Error()
+				continue
+			}
+			result := deleteFromBatch(batch, writer, current, op.id)
+			item.Version, item.SeqNo = result.Version, result.SeqNo
+			docs[op.id] = nil
+			deleted[op.id] = current.Version
+			added = append(added, op)
+			continue
+		}
+
+		mode := model.UpdateModeReplace
+		if op.action == model.BulkActionUpdate {
+			mode = model.UpdateModeMerge
+		}
+		fields, err := applyUpdate(indexName, current, op.id, op.fields, mode)
+		if err != nil {
+			item.Status, item.Error = http.StatusNotFound, err.Error()
+			continue
+		}
+		lastVersion, found := deleted[op.id]
+		if current == nil && !found {
+			if lastVersion, err = loadDeletedVersion(index, op.id); err != nil {
+				item.Status, item.Error = http.StatusInternalServerError, err.Error()
+				continue
+			}
+		}
+		doc, err := addToBatch(batch, writer, current, lastVersion, op.id, fields)
+		if err != nil {
+			item.Status, item.Error = http.StatusBadRequest, err.Error()
+			continue
+		}
+		item.Version, item.SeqNo = doc.Version, doc.SeqNo
+		docs[op.id] = doc
+		added = append(added, op)
+	}
+
+	if err := index.Batch(batch); err != nil {
+		for _, op := range added {
+			item := &items[op.item]
+			item.Status, item.Error = http.StatusInternalServerError, fmt.Sprintf("批量提交失败: %v", err)
+			item.Version, item.SeqNo = 0, 0
+		}
+		return
+	}
+	for _, op := range added {
+		items[op.item].Status = http.StatusOK
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestBulk(t *testing.T) {
+	e := newTestEngine(t)
+	index := newTestIndex(t, e, "bulk_test", bleve.NewIndexMapping())
+	if err := index.Index("3", map[string]interface{}{"name": "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"index": {"id": "1"}}
+{"name": "apple"}
+{"index": {"index_name": "bulk_test", "id": "2"}}
+{"name": "banana", "color": "yellow"}
+
+{"delete": {"id": "3"}}
+{"index": {"id": "4"}}
+not json
+{"index": {"index_name": "missing", "id": "5"}}
+{"name": "cherry"}
+{"update": {}}
+{"name": "durian"}
+{"upsert": {"id": "6"}}
+{"name": "elderberry"}
+{"update": {"id": "2"}}
+{"name": "banana v2"}
+{"delete": {"id": "1", "if_version": 5}}
+`
+	resp, err := e.Bulk(strings.NewReader(body), "bulk_test", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int{
+		http.StatusOK,
+		http.StatusOK,
+		http.StatusOK,
+		http.StatusBadRequest,
+		http.StatusNotFound,
+		http.StatusBadRequest,
+		http.StatusBadRequest,
+		http.StatusOK,
+		http.StatusConflict,
+	}
+	if len(resp.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d items", resp.Items, len(want))
+	}
+	for i, status := range want {
+		if resp.Items[i].Status != status {
+			t.Errorf("item %d status = %d, want %d (%s)", i, resp.Items[i].Status, status, resp.Items[i].Error)
+		}
+	}
+	if !resp.Errors {
+		t.Error("errors = false, want true")
+	}
+
+	count, err := index.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("doc count = %d, want 2", count)
+	}
+	// update 操作合并到已存在的文档
+	doc, err := e.GetDocument("bulk_test", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Fields["name"] != "banana v2" || doc.Fields["color"] != "yellow" || doc.Version != 2 {
+		t.Errorf("fields = %v", doc.Fields)
+	}
+}
+
+func TestBulkReadError(t *testing.T) {
+	e := newTestEngine(t)
+	index := newTestIndex(t, e, "bulk_read_test", bleve.NewIndexMapping())
+
+	// 第一批提交后遇到超长的行
+	body := `{"index": {"id": "1"}}
+{"name": "apple"}
+{"index": {"id": "2"}}
+{"name": "` + strings.Repeat("x", maxBulkLineSize) + `"}
+`
+	resp, err := e.Bulk(strings.NewReader(body), "bulk_read_test", 1)
+	if err == nil {
+		t.Fatal("err = nil, want read error")
+	}
+	if resp == nil || len(resp.Items) == 0 || resp.Items[0].Status != http.StatusOK || !resp.Errors {
+		t.Fatalf("resp = %+v, want committed item 1", resp)
+	}
+	count, err := index.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("doc count = %d, want 1", count)
+	}
+}
+
+func TestBulkInvalidAction(t *testing.T) {
+	e := newTestEngine(t)
+	index := newTestIndex(t, e, "bulk_invalid_test", bleve.NewIndexMapping())
+
+	// 无法解析的元数据行中止请求，其后的文档行不会被当作操作，已提交的操作保留
+	body := `{"index": {"id": "1"}}
+{"name": "apple"}
+{"index": {"id": "3"}}
+{"name": "cherry"}
+{"index": {"id": 2}}
+{"specs": {"color": "red"}}
+{"index": {"id": "2"}}
+{"name": "banana"}
+`
+	resp, err := e.Bulk(strings.NewReader(body), "bulk_invalid_test", 2)
+	if err == nil || !strings.Contains(err.Error(), "第 5 行") {
+		t.Fatalf("err = %v, want error at line 5", err)
+	}
+	if resp == nil || len(resp.Items) != 2 || resp.Items[0].ID != "1" || resp.Items[1].ID != "3" {
+		t.Fatalf("resp = %+v, want committed items 1 and 3", resp)
+	}
+	count, err := index.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("doc count = %d, want 2", count)
+	}
+
+	// 尚未提交的操作不执行
+	body = `{"index": {"id": "4"}}
+{"name": "durian"}
+not json
+`
+	resp, err = e.Bulk(strings.NewReader(body), "bulk_invalid_test", 10)
+	if err == nil || len(resp.Items) != 0 {
+		t.Fatalf("resp = %+v, err = %v, want aborted request without items", resp, err)
+	}
+	if count, _ := index.DocCount(); count != 2 {
+		t.Errorf("doc count = %d, want 2", count)
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"go-search/model"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// 结果中最多返回的失败明细数量
+const maxByQueryFailures = 100
+
+// 删除所有匹配查询条件的文档
+func (e *Engine) DeleteByQuery(indexName string, q model.SearchQuery, opts model.ByQueryOptions) (*model.ByQueryResult, error) {
+	return e.processByQuery(indexName, q, opts, func(batch *bleve.Batch, w *indexWriter, current *model.Document, docID string) error {
+		deleteFromBatch(batch, w, current, docID)
+		return nil
+	})
+}
+
+// 将字段合并到所有匹配查询条件的文档中
+func (e *Engine) UpdateByQuery(indexName string, q model.SearchQuery, fields map[string]interface{}, opts model.ByQueryOptions) (*model.ByQueryResult, error) {
+	if len(fields) == 0 {
+		return nil, invalidRequest("更新字段不能为空")
+	}
+	// 只统计匹配数量时不修改索引映射
+	if !opts.DryRun {
+		if err := e.prepareFields(indexName, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.processByQuery(indexName, q, opts, func(batch *bleve.Batch, w *indexWriter, current *model.Document, docID string) error {
+		// 每个文档使用独立的副本，避免合并后共享嵌套对象
+		_, err := addToBatch(batch, w, current, 0, docID, mergeFields(current.Fields, copyFields(fields)))
+		return err
+	})
+}
+
+// 处理单个匹配文档，将修改加入batch
+type byQueryFunc func(batch *bleve.Batch, w *indexWriter, current *model.Document, docID string) error
+
+// 按文档ID排序分页遍历所有匹配的文档，每页作为一个batch提交
+// 使用search_after翻页，已处理的文档被删除或修改不会影响后续分页
+func (e *Engine) processByQuery(indexName string, q model.SearchQuery, opts model.ByQueryOptions, fn byQueryFunc) (*model.ByQueryResult, error) {
+	searchQuery, err := BuildSearchQuery(q)
+	if err != nil {
+		return nil, asInvalid(err)
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = e.byQueryBatchSize
+	}
+
+	start := time.Now()
+	result := &model.ByQueryResult{DryRun: opts.DryRun}
+
+	if opts.DryRun {
+		total, err := e.countByQuery(indexName, searchQuery)
+		if err != nil {
+			return nil, err
+		}
+		result.Matched = total
+		result.Took = time.Since(start).Milliseconds()
+		return result, nil
+	}
+
+	var after []string
+	for {
+		ids, err := e.nextByQueryPage(indexName, searchQuery, batchSize, after)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		result.Matched += uint64(len(ids))
+		if err := e.applyByQueryPage(indexName, ids, fn, result); err != nil {
+			return nil, err
+		}
+		if len(ids) < batchSize {
+			break
+		}
+		after = []string{ids[len(ids)-1]}
+	}
+
+	result.Took = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// 统计匹配查询条件的文档数量
+func (e *Engine) countByQuery(indexName string, searchQuery query.Query) (uint64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	index, exists := e.indexes[indexName]
+	if !exists {
+		return 0, indexNotFound(indexName)
+	}
+
+	searchRequest := bleve.NewSearchRequestOptions(searchQuery, 0, 0, false)
+	searchResult, err := index.Search(searchRequest)
+	if err != nil {
+		return 0, err
+	}
+	return searchResult.Total, nil
+}
+
+// 获取下一页匹配文档的ID
+func (e *Engine) nextByQueryPage(indexName string, searchQuery query.Query, size int, after []string) ([]string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	index, exists := e.indexes[indexName]
+	if !exists {
+		return nil, indexNotFound(indexName)
+	}
+
+	searchRequest := bleve.NewSearchRequestOptions(searchQuery, size, 0, false)
+	searchRequest.SortBy([]string{"_id"})
+	searchRequest.SearchAfter = after
+	searchResult, err := index.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(searchResult.Hits))
+	for _, hit := range searchResult.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids, nil
+}
+
+// 对一页文档执行修改并作为一个batch提交
+func (e *Engine) applyByQueryPage(indexName string, ids []string, fn byQueryFunc, result *model.ByQueryResult) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	index, exists := e.indexes[indexName]
+	if !exists {
+		return indexNotFound(indexName)
+	}
+	writer := e.writers[indexName]
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	fail := func(docID string, err error) {
+		result.Failed++
+		if len(result.Failures) < maxByQueryFailures {
+			result.Failures = append(result.Failures, model.ByQueryFailure{ID: docID, Error: err.Error()})
+		}
+	}
+
+	batch := index.NewBatch()
+	added := make([]string, 0, len(ids))
+	for _, docID := range ids {
+		current, err := loadDocument(index, docID)
+		if err != nil {
+			fail(docID, err)
+			continue
+		}
+		// 文档在查询之后已被删除
+		if current == nil {
+			fail(docID, documentNotFound(indexName, docID))
+			continue
+		}
+		if err := fn(batch, writer, current, docID); err != nil {
+			fail(docID, err)
+			continue
+		}
+		added = append(added, docID)
+	}
+
+	if err := index.Batch(batch); err != nil {
+		for _, docID := range added {
+			fail(docID, fmt.Errorf("批量提交失败: %v", err))
+		}
+		return nil
+	}
+	result.Changed += uint64(len(added))
+	return nil
+}
+
+// 深拷贝字段，嵌套对象各自独立
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = copyFields(nested)
+		}
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-search/model"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestUpdateAndDeleteByQuery(t *testing.T) {
+	e := newTestEngine(t)
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping.AddFieldMappingsAt("category", bleve.NewKeywordFieldMapping())
+	index := newTestIndex(t, e, "by_query_test", indexMapping)
+
+	for i := 0; i < 25; i++ {
+		category := "phone"
+		if i%5 == 0 {
+			category = "tablet"
+		}
+		doc := model.Document{ID: fmt.Sprintf("%02d", i), Fields: map[string]interface{}{"category": category}}
+		if _, err := e.AddDocument("by_query_test", doc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	phones := model.SearchQuery{Type: 3, DSL: &model.Query{Term: &model.TermQuery{Field: "category", Value: "phone"}}}
+
+	// 试运行只统计数量
+	result, err := e.UpdateByQuery("by_query_test", phones, map[string]interface{}{"on_sale": true}, model.ByQueryOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 20 || result.Changed != 0 {
+		t.Errorf("dry run result = %+v", result)
+	}
+
+	result, err = e.UpdateByQuery("by_query_test", phones, map[string]interface{}{"on_sale": true}, model.ByQueryOptions{BatchSize: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 20 || result.Changed != 20 || result.Failed != 0 {
+		t.Errorf("update result = %+v", result)
+	}
+	doc, err := e.GetDocument("by_query_test", "01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Fields["on_sale"] != true || doc.Fields["category"] != "phone" || doc.Version != 2 {
+		t.Errorf("updated document = %+v", doc)
+	}
+
+	result, err = e.DeleteByQuery("by_query_test", phones, model.ByQueryOptions{BatchSize: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 20 || result.Changed != 20 {
+		t.Errorf("delete result = %+v", result)
+	}
+	count, err := index.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Errorf("doc count = %d, want 5", count)
+	}
+}
+
+func TestUpdateByQueryDryRunKeepsMapping(t *testing.T) {
+	e := newTestEngine(t)
+	opts := model.IndexOptions{DynamicTemplates: []model.DynamicTemplate{{Match: "*_id", Mapping: model.FieldMapping{Type: "keyword"}}}}
+	if err := e.InitIndex("dry_run_test", nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.AddDocument("dry_run_test", model.Document{ID: "1", Fields: map[string]interface{}{"name": "apple"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	all := model.SearchQuery{Type: 3, DSL: &model.Query{MatchAll: &model.MatchAllQuery{}}}
+	result, err := e.UpdateByQuery("dry_run_test", all, map[string]interface{}{"sku_id": "A1"}, model.ByQueryOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 1 || result.Changed != 0 {
+		t.Errorf("dry run result = %+v", result)
+	}
+	// 试运行不按动态模板添加字段
+	if fields := e.writers["dry_run_test"].dynamic.Fields; len(fields) != 0 {
+		t.Errorf("dynamic fields after dry run = %v", fields)
+	}
+
+	if _, err := e.UpdateByQuery("dry_run_test", all, map[string]interface{}{}, model.ByQueryOptions{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("empty fields err = %v, want ErrInvalid", err)
+	}
+}
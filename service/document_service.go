@@ -0,0 +1,390 @@
+package service
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-search/model"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// 根据ID获取文档的存储字段
+func (e *Engine) GetDocument(indexName, docID string) (*model.Document, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	idx, exists := e.indexes[indexName]
+	if !exists {
+		return nil, indexNotFound(indexName)
+	}
+
+	return getDocument(idx, indexName, docID)
+}
+
+// 批量获取文档，单个文档不存在或失败不影响其他文档
+func (e *Engine) MultiGetDocuments(refs []model.DocumentRef) []model.GetResult {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	results := make([]model.GetResult, 0, len(refs))
+	for _, ref := range refs {
+		result := model.GetResult{
+			IndexName: ref.IndexName,
+			ID:        ref.ID,
+		}
+
+		idx, exists := e.indexes[ref.IndexName]
+		if !exists {
+			result.Error = indexNotFound(ref.IndexName).Error()
+			results = append(results, result)
+			continue
+		}
+
+		doc, err := getDocument(idx, ref.IndexName, ref.ID)
+		switch {
+		case err == nil:
+			result.Found = true
+			result.Fields = doc.Fields
+			result.Version, result.SeqNo = doc.Version, doc.SeqNo
+		case !errors.Is(err, ErrNotFound):
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// 从索引中读取文档的存储字段及版本信息，调用方需持有读锁
+func getDocument(idx bleve.Index, indexName, docID string) (*model.Document, error) {
+	doc, err := idx.Document(docID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文档失败: %v", err)
+	}
+	if doc == nil {
+		return nil, documentNotFound(indexName, docID)
+	}
+
+	result := &model.Document{
+		ID:     docID,
+		Fields: storedFields(doc),
+	}
+
+	// 版本信息保存在原始内容记录中，只解析版本相关字段
+	record, err := idx.GetInternal(sourceKey(docID))
+	if err != nil {
+		return nil, fmt.Errorf("读取文档版本失败: %v", err)
+	}
+	if record != nil {
+		var meta struct {
+			Version uint64 `json:"_version"`
+			SeqNo   uint64 `json:"_seq_no"`
+		}
+		if err := json.Unmarshal(record, &meta); err != nil {
+			return nil, fmt.Errorf("解析文档版本失败: %v", err)
+		}
+		result.Version, result.SeqNo = meta.Version, meta.SeqNo
+	}
+
+	return result, nil
+}
+
+// 将bleve文档中的存储字段转换为普通值，同名的多个值合并为数组
+func storedFields(doc index.Document) map[string]interface{} {
+	fields := make(map[string]interface{})
+	doc.VisitFields(func(field index.Field) {
+		var value interface{}
+		switch f := field.(type) {
+		case index.TextField:
+			value = f.Text()
+		case index.NumericField:
+			if num, err := f.Number(); err == nil {
+				value = num
+			}
+		case index.DateTimeField:
+			if datetime, _, err := f.DateTime(); err == nil {
+				value = datetime.Format(time.RFC3339)
+			}
+		case index.BooleanField:
+			if boolean, err := f.Boolean(); err == nil {
+				value = boolean
+			}
+		case index.GeoPointField:
+			lon, lonErr := f.Lon()
+			lat, latErr := f.Lat()
+			if lonErr == nil && latErr == nil {
+				value = []float64{lon, lat}
+			}
+		case index.GeoShapeField:
+			if shape, err := f.GeoShape(); err == nil {
+				value = shape
+			}
+		case index.IPField:
+			if ip, err := f.IP(); err == nil {
+				value = ip.String()
+			}
+		}
+		if value == nil {
+			return
+		}
+
+		switch existing := fields[field.Name()].(type) {
+		case nil:
+			fields[field.Name()] = value
+		case []interface{}:
+			fields[field.Name()] = append(existing, value)
+		default:
+			fields[field.Name()] = []interface{}{existing, value}
+		}
+	})
+	return fields
+}
+
+const (
+	// 文档原始内容及版本信息在索引内部存储中的键前缀
+	sourceKeyPrefix = "_source/"
+	// 已删除文档最后的版本号在内部存储中的键前缀，重新创建时版本号在此基础上递增
+	deletedKeyPrefix = "_deleted/"
+	// 索引最近一次写入的序列号在内部存储中的键
+	seqNoKey = "_seq_no"
+)
+
+func sourceKey(docID string) []byte {
+	return []byte(sourceKeyPrefix + docID)
+}
+
+func deletedKey(docID string) []byte {
+	return []byte(deletedKeyPrefix + docID)
+}
+
+// 索引的写入状态，串行化同一索引上的写操作，保证版本检查与写入是原子的
+type indexWriter struct {
+	mu      sync.Mutex
+	seqNo   uint64          // 最近一次写入的序列号
+	dynamic *dynamicMapping // 动态映射设置，修改时需持有 Engine 的写锁
+
+	keywords []model.KeywordExtraction // 关键词提取设置，创建索引后不再修改
+}
+
+// 从索引内部存储中恢复写入状态
+func newIndexWriter(idx bleve.Index) (*indexWriter, error) {
+	data, err := idx.GetInternal([]byte(seqNoKey))
+	if err != nil {
+		return nil, fmt.Errorf("读取序列号失败: %v", err)
+	}
+	w := &indexWriter{}
+	if len(data) == 8 {
+		w.seqNo = binary.BigEndian.Uint64(data)
+	}
+	if w.dynamic, err = loadDynamicMapping(idx); err != nil {
+		return nil, err
+	}
+	if w.keywords, err = loadKeywordExtractions(idx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// 分配新的序列号，并在同一个batch中持久化
+func (w *indexWriter) nextSeqNo(batch *bleve.Batch) uint64 {
+	w.seqNo++
+	batch.SetInternal([]byte(seqNoKey), binary.BigEndian.AppendUint64(nil, w.seqNo))
+	return w.seqNo
+}
+
+// 读取文档的原始内容及版本信息，文档不存在时返回nil
+// 对于保存原始内容之前写入的文档，退回使用存储字段，版本为0
+func loadDocument(idx bleve.Index, docID string) (*model.Document, error) {
+	data, err := idx.GetInternal(sourceKey(docID))
+	if err != nil {
+		return nil, fmt.Errorf("读取文档原始内容失败: %v", err)
+	}
+	if data != nil {
+		var doc model.Document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("解析文档原始内容失败: %v", err)
+		}
+		return &doc, nil
+	}
+
+	doc, err := idx.Document(docID)
+	if err != nil {
+		return nil, fmt.Errorf("获取文档失败: %v", err)
+	}
+	if doc == nil {
+		return nil, nil
+	}
+	return &model.Document{ID: docID, Fields: storedFields(doc)}, nil
+}
+
+// 读取已删除文档最后的版本号，文档未被删除过时返回0
+func loadDeletedVersion(idx bleve.Index, docID string) (uint64, error) {
+	data, err := idx.GetInternal(deletedKey(docID))
+	if err != nil {
+		return 0, fmt.Errorf("读取已删除文档的版本失败: %v", err)
+	}
+	if len(data) != 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// 检查文档当前版本是否与期望版本一致，文档不存在时当前版本为0
+func checkVersion(current *model.Document, docID string, ifVersion *uint64) error {
+	if ifVersion == nil {
+		return nil
+	}
+	var version uint64
+	if current != nil {
+		version = current.Version
+	}
+	if *ifVersion != version {
+		return versionConflict(docID, *ifVersion, version)
+	}
+	return nil
+}
+
+// 根据更新模式计算写入的字段
+// replace: 整体覆盖; merge: 将字段深度合并到已存在的文档; upsert: 文档存在时合并，否则新建
+func applyUpdate(indexName string, current *model.Document, docID string, fields map[string]interface{}, mode string) (map[string]interface{}, error) {
+	switch mode {
+	case "", model.UpdateModeReplace:
+		return fields, nil
+	case model.UpdateModeMerge, model.UpdateModeUpsert:
+		if current != nil {
+			return mergeFields(current.Fields, fields), nil
+		}
+		if mode == model.UpdateModeMerge {
+			return nil, documentNotFound(indexName, docID)
+		}
+		return fields, nil
+	default:
+		return nil, fmt.Errorf("不支持的更新模式: %s", mode)
+	}
+}
+
+// 将文档及其原始内容加入batch，版本号在当前版本基础上加一并分配新的序列号
+// 文档不存在时 deleted 为其被删除前最后的版本号(未删除过为0)，重新创建的文档版本号不会倒退
+// 调用方需持有索引写锁
+func addToBatch(batch *bleve.Batch, w *indexWriter, current *model.Document, deleted uint64, docID string, fields map[string]interface{}) (*model.Document, error) {
+	doc := &model.Document{
+		ID:      docID,
+		Fields:  fields,
+		Version: deleted + 1,
+		SeqNo:   w.seqNo + 1,
+	}
+	if current != nil {
+		doc.Version = current.Version + 1
+	}
+
+	record, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("序列化文档失败: %v", err)
+	}
+	indexed, err := w.indexedFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := batch.Index(docID, indexed); err != nil {
+		return nil, err
+	}
+	batch.SetInternal(sourceKey(docID), record)
+	if current == nil && deleted > 0 {
+		batch.DeleteInternal(deletedKey(docID))
+	}
+	w.nextSeqNo(batch)
+	return doc, nil
+}
+
+// 将已存在文档的删除操作加入batch，调用方需持有索引写锁
+// 保留文档最后的版本号，避免使用旧版本号的条件写入在文档重新创建后误匹配
+func deleteFromBatch(batch *bleve.Batch, w *indexWriter, current *model.Document, docID string) *model.WriteResult {
+	batch.Delete(docID)
+	batch.DeleteInternal(sourceKey(docID))
+	if current.Version > 0 {
+		batch.SetInternal(deletedKey(docID), binary.BigEndian.AppendUint64(nil, current.Version))
+	}
+	return &model.WriteResult{ID: docID, Version: current.Version, SeqNo: w.nextSeqNo(batch)}
+}
+
+// 检查版本后写入单个文档
+func writeDocument(idx bleve.Index, w *indexWriter, indexName string, doc model.Document, mode string, ifVersion *uint64) (*model.WriteResult, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current, err := loadDocument(idx, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, doc.ID, ifVersion); err != nil {
+		return nil, err
+	}
+	fields, err := applyUpdate(indexName, current, doc.ID, doc.Fields, mode)
+	if err != nil {
+		return nil, err
+	}
+	var deleted uint64
+	if current == nil {
+		if deleted, err = loadDeletedVersion(idx, doc.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := idx.NewBatch()
+	written, err := addToBatch(batch, w, current, deleted, doc.ID, fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.Batch(batch); err != nil {
+		return nil, err
+	}
+
+	return &model.WriteResult{ID: written.ID, Version: written.Version, SeqNo: written.SeqNo}, nil
+}
+
+// 检查版本后删除单个文档
+func deleteDocument(idx bleve.Index, w *indexWriter, indexName, docID string, ifVersion *uint64) (*model.WriteResult, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	current, err := loadDocument(idx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, docID, ifVersion); err != nil {
+		return nil, err
+	}
+	// 文档不存在时不写入，也不占用序列号
+	if current == nil {
+		return nil, documentNotFound(indexName, docID)
+	}
+
+	batch := idx.NewBatch()
+	result := deleteFromBatch(batch, w, current, docID)
+	if err := idx.Batch(batch); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 将src深度合并到dst，嵌套对象递归合并，其他值直接覆盖
+func mergeFields(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			dst[key] = mergeFields(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
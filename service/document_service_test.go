@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"go-search/model"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestGetDocument(t *testing.T) {
+	e := newTestEngine(t)
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping.AddFieldMappingsAt("category", bleve.NewKeywordFieldMapping())
+	index := newTestIndex(t, e, "get_test", indexMapping)
+
+	fields := map[string]interface{}{
+		"name":     "iPhone 13",
+		"category": "phone",
+		"price":    5999,
+		"tags":     []string{"apple", "5g"},
+		"on_sale":  true,
+	}
+	if err := index.Index("1", fields); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := e.GetDocument("get_test", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Fields["name"] != "iPhone 13" || doc.Fields["category"] != "phone" {
+		t.Errorf("fields = %v", doc.Fields)
+	}
+	if doc.Fields["price"] != 5999.0 || doc.Fields["on_sale"] != true {
+		t.Errorf("fields = %v", doc.Fields)
+	}
+	if tags, ok := doc.Fields["tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("tags = %v", doc.Fields["tags"])
+	}
+
+	if _, err := e.GetDocument("get_test", "2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+
+	results := e.MultiGetDocuments([]model.DocumentRef{
+		{IndexName: "get_test", ID: "1"},
+		{IndexName: "get_test", ID: "2"},
+		{IndexName: "missing", ID: "1"},
+	})
+	if !results[0].Found || results[0].Fields["name"] != "iPhone 13" {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Found || results[1].Error != "" {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+	if results[2].Found || results[2].Error == "" {
+		t.Errorf("results[2] = %+v", results[2])
+	}
+}
+
+func TestUpdateDocumentModes(t *testing.T) {
+	e := newTestEngine(t)
+	newTestIndex(t, e, "update_test", bleve.NewIndexMapping())
+
+	_, err := e.AddDocument("update_test", model.Document{ID: "1", Fields: map[string]interface{}{
+		"name":  "iPhone 13",
+		"price": 5999,
+		"specs": map[string]interface{}{"color": "星光色", "storage": "128GB"},
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// merge 只修改传入的字段，嵌套对象递归合并
+	_, err = e.UpdateDocument("update_test", model.Document{ID: "1", Fields: map[string]interface{}{
+		"price": 5499,
+		"specs": map[string]interface{}{"color": "午夜色"},
+	}}, model.UpdateModeMerge, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := e.GetDocument("update_test", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Fields["name"] != "iPhone 13" || doc.Fields["price"] != 5499.0 ||
+		doc.Fields["specs.color"] != "午夜色" || doc.Fields["specs.storage"] != "128GB" {
+		t.Errorf("merged fields = %v", doc.Fields)
+	}
+
+	// merge 要求文档已存在
+	_, err = e.UpdateDocument("update_test", model.Document{ID: "2", Fields: map[string]interface{}{"name": "iPad"}}, model.UpdateModeMerge, nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("merge missing document err = %v, want ErrNotFound", err)
+	}
+
+	// upsert 在文档不存在时新建
+	_, err = e.UpdateDocument("update_test", model.Document{ID: "2", Fields: map[string]interface{}{"name": "iPad"}}, model.UpdateModeUpsert, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.GetDocument("update_test", "2"); err != nil {
+		t.Errorf("upserted document not found: %v", err)
+	}
+
+	// replace 整体覆盖
+	_, err = e.UpdateDocument("update_test", model.Document{ID: "1", Fields: map[string]interface{}{"name": "iPhone 15"}}, model.UpdateModeReplace, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = e.GetDocument("update_test", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Fields["price"]; ok || doc.Fields["name"] != "iPhone 15" {
+		t.Errorf("replaced fields = %v", doc.Fields)
+	}
+}
+
+func TestDocumentVersions(t *testing.T) {
+	e := newTestEngine(t)
+	newTestIndex(t, e, "version_test", bleve.NewIndexMapping())
+
+	zero := uint64(0)
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{"name": "iPhone 13"}}
+
+	// if_version 为0表示文档必须不存在
+	result, err := e.AddDocument("version_test", doc, &zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 1 || result.SeqNo != 1 {
+		t.Errorf("result = %+v, want version 1 seq_no 1", result)
+	}
+	if _, err := e.AddDocument("version_test", doc, &zero); !errors.Is(err, ErrConflict) {
+		t.Errorf("create existing document err = %v, want ErrConflict", err)
+	}
+
+	// 版本一致时更新成功，版本号加一
+	one := uint64(1)
+	result, err = e.UpdateDocument("version_test", model.Document{ID: "1", Fields: map[string]interface{}{"price": 5999}}, model.UpdateModeMerge, &one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 2 || result.SeqNo != 2 {
+		t.Errorf("result = %+v, want version 2 seq_no 2", result)
+	}
+
+	// 过期的版本返回冲突
+	_, err = e.UpdateDocument("version_test", model.Document{ID: "1", Fields: map[string]interface{}{"price": 5499}}, model.UpdateModeMerge, &one)
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("stale update err = %v, want ErrConflict", err)
+	}
+	if _, err := e.DeleteDocument("version_test", "1", &one); !errors.Is(err, ErrConflict) {
+		t.Errorf("stale delete err = %v, want ErrConflict", err)
+	}
+
+	got, err := e.GetDocument("version_test", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 2 || got.SeqNo != 2 || got.Fields["price"] != 5999.0 {
+		t.Errorf("document = %+v", got)
+	}
+
+	// 序列号在索引内单调递增
+	result, err = e.AddDocument("version_test", model.Document{ID: "2", Fields: map[string]interface{}{"name": "iPad"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 1 || result.SeqNo != 3 {
+		t.Errorf("result = %+v, want version 1 seq_no 3", result)
+	}
+}
+
+func TestDocumentVersionsAfterDelete(t *testing.T) {
+	e := newTestEngine(t)
+	newTestIndex(t, e, "recreate_test", bleve.NewIndexMapping())
+
+	one := uint64(1)
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{"name": "iPhone 13"}}
+	if _, err := e.AddDocument("recreate_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.DeleteDocument("recreate_test", "1", &one); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新创建的文档版本号在删除前的版本基础上递增
+	result, err := e.AddDocument("recreate_test", doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 2 {
+		t.Errorf("recreated version = %d, want 2", result.Version)
+	}
+
+	// 删除前读取的旧版本不能匹配重新创建的文档
+	_, err = e.UpdateDocument("recreate_test", model.Document{ID: "1", Fields: map[string]interface{}{"price": 5999}}, model.UpdateModeMerge, &one)
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("stale update err = %v, want ErrConflict", err)
+	}
+
+	// 批量写入中同一批次内的删除和重新创建
+	body := `{"delete": {"id": "1"}}
+{"index": {"id": "1"}}
+{"name": "iPhone 15"}
+`
+	resp, err := e.Bulk(strings.NewReader(body), "recreate_test", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 2 || resp.Items[1].Version != 3 {
+		t.Errorf("bulk items = %+v, want recreated version 3", resp.Items)
+	}
+
+	// 不同批次提交时从已保存的版本继续
+	body = `{"delete": {"id": "1"}}
+{"index": {"id": "1"}}
+{"name": "iPhone 16"}
+`
+	resp, err = e.Bulk(strings.NewReader(body), "recreate_test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 2 || resp.Items[1].Version != 4 {
+		t.Errorf("bulk items = %+v, want recreated version 4", resp.Items)
+	}
+}
+
+func TestDeleteMissingDocument(t *testing.T) {
+	e := newTestEngine(t)
+	newTestIndex(t, e, "delete_missing_test", bleve.NewIndexMapping())
+
+	if _, err := e.AddDocument("delete_missing_test", model.Document{ID: "1", Fields: map[string]interface{}{"name": "apple"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.DeleteDocument("delete_missing_test", "nope", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete missing document err = %v, want ErrNotFound", err)
+	}
+
+	resp, err := e.Bulk(strings.NewReader(`{"delete": {"id": "nope"}}`+"\n"), "delete_missing_test", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].Status != http.StatusNotFound {
+		t.Errorf("bulk items = %+v, want status 404", resp.Items)
+	}
+
+	// 删除不存在的文档不占用序列号
+	result, err := e.AddDocument("delete_missing_test", model.Document{ID: "2", Fields: map[string]interface{}{"name": "banana"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SeqNo != 2 {
+		t.Errorf("seq_no = %d, want 2", result.SeqNo)
+	}
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-search/model"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// 动态映射设置在索引内部存储中的键
+const dynamicKey = "_dynamic"
+
+// 索引的动态映射设置
+// bleve 只在创建索引时保存映射，按动态模板添加的字段也保存在这里，打开索引时重新加入映射
+type dynamicMapping struct {
+	Mode      string                        `json:"mode"`
+	Templates []model.DynamicTemplate       `json:"templates,omitempty"`
+	Fields    map[string]model.FieldMapping `json:"fields,omitempty"` // 按动态模板添加的字段
+}
+
+// 校验创建索引时指定的动态映射设置
+func (e *Engine) newDynamicMapping(opts model.IndexOptions) (*dynamicMapping, error) {
+	mode := opts.Dynamic
+	if mode == "" {
+		mode = model.DynamicTrue
+	}
+	switch mode {
+	case model.DynamicTrue, model.DynamicFalse, model.DynamicStrict:
+	default:
+		return nil, fmt.Errorf("dynamic 只能是 true、false 或 strict: %s", mode)
+	}
+	if len(opts.DynamicTemplates) > 0 && mode != model.DynamicTrue {
+		return nil, fmt.Errorf("dynamic 为 true 时才能使用 dynamic_templates")
+	}
+
+	for i, template := range opts.DynamicTemplates {
+		if err := e.checkDynamicTemplate(template); err != nil {
+			return nil, fmt.Errorf("第 %d 个动态模板不合法: %v", i+1, err)
+		}
+	}
+
+	return &dynamicMapping{Mode: mode, Templates: opts.DynamicTemplates}, nil
+}
+
+func (e *Engine) checkDynamicTemplate(template model.DynamicTemplate) error {
+	if template.Match == "" && template.PathMatch == "" && template.MatchMappingType == "" {
+		return fmt.Errorf("必须指定 match、path_match 或 match_mapping_type")
+	}
+	for _, pattern := range []string{template.Match, template.PathMatch} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("匹配模式 %s 不合法", pattern)
+		}
+	}
+	switch template.MatchMappingType {
+	case "", model.JSONTypeString, model.JSONTypeNumber, model.JSONTypeBoolean:
+	default:
+		return fmt.Errorf("match_mapping_type 只能是 string、number 或 boolean: %s", template.MatchMappingType)
+	}
+	if template.Mapping.Type == model.FieldTypeObject || len(template.Mapping.Properties) > 0 {
+		return fmt.Errorf("mapping 不能是 object 类型")
+	}
+	// 在临时映射上构建一次，提前发现字段配置错误
+	if _, err := e.buildFieldMapping(bleve.NewIndexMapping(), "dynamic_template", template.Mapping); err != nil {
+		return fmt.Errorf("mapping 不合法: %v", err)
+	}
+	return nil
+}
+
+// 读取索引的动态映射设置，没有保存设置的索引为 dynamic: true
+func loadDynamicMapping(idx bleve.Index) (*dynamicMapping, error) {
+	data, err := idx.GetInternal([]byte(dynamicKey))
+	if err != nil {
+		return nil, fmt.Errorf("读取动态映射设置失败: %v", err)
+	}
+	dynamic := &dynamicMapping{Mode: model.DynamicTrue}
+	if data != nil {
+		if err := json.Unmarshal(data, dynamic); err != nil {
+			return nil, fmt.Errorf("解析动态映射设置失败: %v", err)
+		}
+	}
+	return dynamic, nil
+}
+
+func saveDynamicMapping(idx bleve.Index, dynamic *dynamicMapping) error {
+	data, err := json.Marshal(dynamic)
+	if err != nil {
+		return err
+	}
+	if err := idx.SetInternal([]byte(dynamicKey), data); err != nil {
+		return fmt.Errorf("保存动态映射设置失败: %v", err)
+	}
+	return nil
+}
+
+// 将按动态模板添加的字段重新加入打开的索引的映射
+func (e *Engine) restoreDynamicFields(idx bleve.Index, dynamic *dynamicMapping) error {
+	indexMapping, ok := idx.Mapping().(*mapping.IndexMappingImpl)
+	if !ok || len(dynamic.Fields) == 0 {
+		return nil
+	}
+	if err := e.addFieldMappings(indexMapping, indexMapping.DefaultMapping, "", dynamic.Fields); err != nil {
+		return fmt.Errorf("恢复动态字段失败: %v", err)
+	}
+	return nil
+}
+
+// 写入文档前检查未定义的字段
+// strict 索引中存在未定义的字段时返回 ErrInvalid，dynamic 为 true 时为匹配动态模板的字段添加映射
+func (e *Engine) prepareFields(indexName string, fields map[string]interface{}) error {
+	e.mu.RLock()
+	index, exists := e.indexes[indexName]
+	var added map[string]model.FieldMapping
+	var err error
+	if exists {
+		added, err = e.writers[indexName].dynamic.check(index.Mapping(), fields)
+	}
+	e.mu.RUnlock()
+	if err != nil || len(added) == 0 {
+		return err
+	}
+
+	// 修改映射时持有写锁，避免与使用映射的搜索和写入并发
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// 索引不存在时由之后的写入操作返回错误
+	index, exists = e.indexes[indexName]
+	if !exists {
+		return nil
+	}
+	return e.addDynamicFields(index, e.writers[indexName].dynamic, added)
+}
+
+// 将匹配动态模板的字段加入映射并保存，调用方需持有写锁
+func (e *Engine) addDynamicFields(idx bleve.Index, dynamic *dynamicMapping, fields map[string]model.FieldMapping) error {
+	indexMapping, ok := idx.Mapping().(*mapping.IndexMappingImpl)
+	if !ok {
+		return nil
+	}
+
+	paths := make([]string, 0, len(fields))
+	for fieldPath := range fields {
+		// 并发的写入可能已经添加了相同的字段
+		if _, exists := dynamic.Fields[fieldPath]; !exists {
+			paths = append(paths, fieldPath)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	sort.Strings(paths)
+
+	var addErr error
+	for _, fieldPath := range paths {
+		field := map[string]model.FieldMapping{fieldPath: fields[fieldPath]}
+		if err := e.addFieldMappings(indexMapping, indexMapping.DefaultMapping, "", field); err != nil {
+			addErr = invalidRequest("按动态模板添加字段失败: %v", err)
+			break
+		}
+		if dynamic.Fields == nil {
+			dynamic.Fields = make(map[string]model.FieldMapping)
+		}
+		dynamic.Fields[fieldPath] = fields[fieldPath]
+	}
+
+	if err := saveDynamicMapping(idx, dynamic); err != nil {
+		return err
+	}
+	return addErr
+}
+
+// 检查文档中未在映射中定义的字段，返回需要按动态模板添加的字段映射
+func (d *dynamicMapping) check(m mapping.IndexMapping, fields map[string]interface{}) (map[string]model.FieldMapping, error) {
+	indexMapping, ok := m.(*mapping.IndexMappingImpl)
+	if !ok {
+		return nil, nil
+	}
+	strict := d.Mode == model.DynamicStrict
+	if !strict && (d.Mode != model.DynamicTrue || len(d.Templates) == 0) {
+		return nil, nil
+	}
+
+	var unknown []string
+	var added map[string]model.FieldMapping
+	walkUnmappedFields(indexMapping.DefaultMapping, "", fields, func(fieldPath string, value interface{}) {
+		if strict {
+			unknown = append(unknown, fieldPath)
+			return
+		}
+		if _, exists := added[fieldPath]; exists {
+			return
+		}
+		if field, ok := d.match(fieldPath, value); ok {
+			if added == nil {
+				added = make(map[string]model.FieldMapping)
+			}
+			added[fieldPath] = field
+		}
+	})
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		unknown = slices.Compact(unknown)
+		return nil, invalidRequest("字段 %s 未在映射中定义", strings.Join(unknown, ", "))
+	}
+	return added, nil
+}
+
+// 第一个匹配字段的动态模板对应的映射
+func (d *dynamicMapping) match(fieldPath string, value interface{}) (model.FieldMapping, bool) {
+	name := fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+	valueType := jsonType(value)
+	for _, template := range d.Templates {
+		if template.Match != "" {
+			if ok, _ := path.Match(template.Match, name); !ok {
+				continue
+			}
+		}
+		if template.PathMatch != "" {
+			if ok, _ := path.Match(template.PathMatch, fieldPath); !ok {
+				continue
+			}
+		}
+		if template.MatchMappingType != "" && template.MatchMappingType != valueType {
+			continue
+		}
+		return template.Mapping, true
+	}
+	return model.FieldMapping{}, false
+}
+
+// 遍历文档中未在映射中定义的字段，数组按元素处理，嵌套对象递归处理
+// 字段名中的点号与嵌套对象等价，与 bleve 建立索引时的规则一致
+func walkUnmappedFields(docMapping *mapping.DocumentMapping, prefix string, fields map[string]interface{}, fn func(fieldPath string, value interface{})) {
+	for key, value := range fields {
+		fieldPath := key
+		if prefix != "" {
+			fieldPath = prefix + "." + key
+		}
+		sub := docMapping
+		for _, segment := range strings.Split(key, ".") {
+			if sub != nil {
+				sub = sub.Properties[segment]
+			}
+		}
+		walkUnmappedValue(sub, fieldPath, value, fn)
+	}
+}
+
+func walkUnmappedValue(docMapping *mapping.DocumentMapping, fieldPath string, value interface{}, fn func(fieldPath string, value interface{})) {
+	// 已定义的字段，包括值为对象的 geopoint 等字段
+	if docMapping != nil && len(docMapping.Fields) > 0 {
+		return
+	}
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		walkUnmappedFields(docMapping, fieldPath, v, fn)
+	case []interface{}:
+		for _, item := range v {
+			walkUnmappedValue(docMapping, fieldPath, item, fn)
+		}
+	default:
+		fn(fieldPath, v)
+	}
+}
+
+// 字段值的 JSON 类型
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return model.JSONTypeString
+	case bool:
+		return model.JSONTypeBoolean
+	case float64, float32, int, int64, int32, uint64, uint32, json.Number:
+		return model.JSONTypeNumber
+	default:
+		return ""
+	}
+}
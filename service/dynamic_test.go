@@ -0,0 +1,197 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"go-search/model"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// 按查询条件返回匹配的文档数量
+func countMatches(t *testing.T, e *Engine, indexName string, q *model.Query) uint64 {
+	t.Helper()
+	result, err := e.QuerySearch(indexName, q, model.SearchOptions{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Total
+}
+
+func TestDynamicStrict(t *testing.T) {
+	e := newTestEngine(t)
+	fields := map[string]model.FieldMapping{
+		"title":       {Type: "jieba"},
+		"specs.color": {Type: model.FieldTypeKeyword},
+		"location":    {Type: model.FieldTypeGeoPoint},
+	}
+	if err := e.InitIndex("strict_test", fields, model.IndexOptions{Dynamic: model.DynamicStrict}); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{
+		"title":    "小米手机",
+		"specs":    map[string]interface{}{"color": "black"},
+		"location": map[string]interface{}{"lat": 39.9, "lon": 116.4},
+	}}
+	if _, err := e.AddDocument("strict_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fields := range []map[string]interface{}{
+		{"titel": "小米手机"},
+		{"specs": map[string]interface{}{"size": "6.1"}},
+		{"specs.weight": 180},
+		{"tags": []interface{}{"phone"}},
+	} {
+		_, err := e.AddDocument("strict_test", model.Document{ID: "2", Fields: fields}, nil)
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("AddDocument(%v) err = %v, want ErrInvalid", fields, err)
+		}
+	}
+	if _, err := e.UpdateDocument("strict_test", model.Document{ID: "1", Fields: map[string]interface{}{"price": 1999}}, model.UpdateModeMerge, nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("UpdateDocument err = %v, want ErrInvalid", err)
+	}
+	q := model.SearchQuery{Type: 3, DSL: &model.Query{MatchAll: &model.MatchAllQuery{}}}
+	if _, err := e.UpdateByQuery("strict_test", q, map[string]interface{}{"on_sale": true}, model.ByQueryOptions{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("UpdateByQuery err = %v, want ErrInvalid", err)
+	}
+
+	bulk := `{"index": {"id": "2"}}
+{"title": "华为平板"}
+{"index": {"id": "3"}}
+{"title": "华为手机", "brand": "huawei"}
+`
+	resp, err := e.Bulk(strings.NewReader(bulk), "strict_test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Items[0].Status != http.StatusOK || resp.Items[1].Status != http.StatusBadRequest {
+		t.Errorf("bulk items = %+v", resp.Items)
+	}
+}
+
+func TestDynamicFalse(t *testing.T) {
+	e := newTestEngine(t)
+	fields := map[string]model.FieldMapping{"title": {Type: "jieba"}, "specs.color": {Type: model.FieldTypeKeyword}}
+	if err := e.InitIndex("ignore_test", fields, model.IndexOptions{Dynamic: model.DynamicFalse}); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{
+		"title": "小米手机",
+		"note":  "hidden",
+		"specs": map[string]interface{}{"color": "black", "size": "large"},
+	}}
+	if _, err := e.AddDocument("ignore_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未定义的字段不建立索引，但保存在原始文档中
+	if n := countMatches(t, e, "ignore_test", &model.Query{Term: &model.TermQuery{Field: "note", Value: "hidden"}}); n != 0 {
+		t.Errorf("note total = %d, want 0", n)
+	}
+	if n := countMatches(t, e, "ignore_test", &model.Query{Term: &model.TermQuery{Field: "specs.size", Value: "large"}}); n != 0 {
+		t.Errorf("specs.size total = %d, want 0", n)
+	}
+	if n := countMatches(t, e, "ignore_test", &model.Query{Term: &model.TermQuery{Field: "specs.color", Value: "black"}}); n != 1 {
+		t.Errorf("specs.color total = %d, want 1", n)
+	}
+	source, err := loadDocument(e.indexes["ignore_test"], "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.Fields["note"] != "hidden" {
+		t.Errorf("source fields = %v", source.Fields)
+	}
+}
+
+func TestDynamicTemplates(t *testing.T) {
+	var templates []model.DynamicTemplate
+	err := json.Unmarshal([]byte(`[
+		{"match": "*_id", "mapping": {"type": "keyword"}},
+		{"match": "*_zh", "match_mapping_type": "string", "mapping": "jieba"},
+		{"path_match": "stats.*", "match_mapping_type": "number", "mapping": {"type": "number"}}
+	]`), &templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngine(WithDataDir(t.TempDir()))
+	opts := model.IndexOptions{DynamicTemplates: templates}
+	if err := e.InitIndex("templates_test", nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{
+		"user_id":  "AB-12 CD",
+		"title_zh": "我爱北京天安门",
+		"stats":    map[string]interface{}{"views": 42},
+	}}
+	if _, err := e.AddDocument("templates_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := map[string]*model.Query{
+		"keyword": {Match: &model.MatchQuery{Field: "user_id", Query: "AB-12 CD"}},
+		"jieba":   {Match: &model.MatchQuery{Field: "title_zh", Query: "天安门"}},
+		"number":  {NumericRange: &model.NumericRangeQuery{Field: "stats.views", GTE: new(float64)}},
+	}
+	check := func(e *Engine) {
+		t.Helper()
+		for name, q := range queries {
+			if n := countMatches(t, e, "templates_test", q); n != 1 {
+				t.Errorf("%s total = %d, want 1", name, n)
+			}
+		}
+	}
+	check(e)
+
+	info, err := e.GetIndex("templates_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Dynamic != model.DynamicTrue || len(info.DynamicTemplates) != 3 {
+		t.Errorf("index info = %+v", info)
+	}
+
+	// 重新打开索引后，按模板添加的字段映射仍然有效
+	if err := e.CloseIndex("templates_test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.OpenIndex("templates_test"); err != nil {
+		t.Fatal(err)
+	}
+	defer e.CloseAll()
+	check(e)
+}
+
+func TestDynamicOptionsInvalid(t *testing.T) {
+	e := newTestEngine(t)
+	keyword := model.FieldMapping{Type: model.FieldTypeKeyword}
+
+	tests := map[string]model.IndexOptions{
+		"unknown mode":        {Dynamic: "sometimes"},
+		"templates on strict": {Dynamic: model.DynamicStrict, DynamicTemplates: []model.DynamicTemplate{{Match: "*_id", Mapping: keyword}}},
+		"no condition":        {DynamicTemplates: []model.DynamicTemplate{{Mapping: keyword}}},
+		"bad pattern":         {DynamicTemplates: []model.DynamicTemplate{{Match: "[", Mapping: keyword}}},
+		"bad json type":       {DynamicTemplates: []model.DynamicTemplate{{MatchMappingType: "object", Mapping: keyword}}},
+		"object mapping":      {DynamicTemplates: []model.DynamicTemplate{{Match: "*", Mapping: model.FieldMapping{Type: model.FieldTypeObject}}}},
+		"bad mapping":         {DynamicTemplates: []model.DynamicTemplate{{Match: "*", Mapping: model.FieldMapping{Type: model.FieldTypeNumber, Analyzer: "jieba"}}}},
+	}
+	for name, opts := range tests {
+		if err := e.InitIndex("invalid_test", nil, opts); err == nil {
+			t.Errorf("%s: InitIndex succeeded", name)
+		}
+	}
+
+	var dynamic model.Dynamic
+	for input, want := range map[string]model.Dynamic{`true`: "true", `false`: "false", `"strict"`: "strict"} {
+		if err := json.Unmarshal([]byte(input), &dynamic); err != nil || dynamic != want {
+			t.Errorf("unmarshal %s = %q, %v", input, dynamic, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`"loose"`), &dynamic); err == nil {
+		t.Error("unmarshal \"loose\" succeeded")
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"fmt"
+	"go-search/analysis/jieba"
+	"go-search/model"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+const (
+	// 默认索引数据目录
+	DefaultDataDir = "./data"
+	// 字段统计时默认扫描的最大文档数量
+	DefaultStatsScanSize = 10000
+	// 索引目录中标记索引已关闭的文件，重启后索引保持关闭状态
+	closedMarkerFile = ".closed"
+)
+
+var indexNameRegex = regexp.MustCompile(`^[a-zA-Z_.]+$`)
+
+// 搜索引擎，管理一组索引及其读写
+// 同一进程中可以创建多个互不影响的 Engine
+type Engine struct {
+	dataDir          string
+	inMemory         bool              // 内存模式，索引不写入磁盘
+	analyzers        map[string]string // 字段配置中的分词器名称到 bleve 分析器的映射
+	bulkBatchSize    int
+	byQueryBatchSize int
+	statsScanSize    int
+
+	mu            sync.RWMutex
+	indexes       map[string]bleve.Index
+	writers       map[string]*indexWriter // 每个已打开索引的写入状态
+	closedIndexes map[string]struct{}     // 已关闭的索引，数据仍保留在磁盘上
+	memoryIndexes map[string]struct{}     // 只保存在内存中的索引
+}
+
+// Engine 构造选项
+type Option func(*Engine)
+
+// 设置索引数据目录
+func WithDataDir(dir string) Option {
+	return func(e *Engine) {
+		if dir != "" {
+			e.dataDir = dir
+		}
+	}
+}
+
+// 使用内存模式，所有索引只保存在内存中，适用于测试及临时数据
+// 非内存模式下也可以在创建索引时单独指定使用内存存储
+func WithInMemory() Option {
+	return func(e *Engine) {
+		e.inMemory = true
+	}
+}
+
+// 注册字段配置中可用的分词器，name 为创建索引时使用的名称，analyzer 为已注册的 bleve 分析器
+func WithAnalyzer(name, analyzer string) Option {
+	return func(e *Engine) {
+		e.analyzers[name] = analyzer
+	}
+}
+
+// 设置批量写入默认每批提交的操作数量
+func WithBulkBatchSize(size int) Option {
+	return func(e *Engine) {
+		if size > 0 {
+			e.bulkBatchSize = size
+		}
+	}
+}
+
+// 设置按查询删除/更新默认每批处理的文档数量
+func WithByQueryBatchSize(size int) Option {
+	return func(e *Engine) {
+		if size > 0 {
+			e.byQueryBatchSize = size
+		}
+	}
+}
+
+// 设置数字字段范围分布统计时扫描的最大文档数量
+func WithStatsScanSize(size int) Option {
+	return func(e *Engine) {
+		if size > 0 {
+			e.statsScanSize = size
+		}
+	}
+}
+
+// 创建搜索引擎，创建后需调用 LoadAllIndexes 加载数据目录中已存在的索引
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{
+		dataDir: DefaultDataDir,
+		analyzers: map[string]string{
+			"jieba":     jieba.AnalyzerName,
+			"jieba_t2s": jieba.T2SAnalyzerName,
+		},
+		bulkBatchSize:    DefaultBulkBatchSize,
+		byQueryBatchSize: DefaultBulkBatchSize,
+		statsScanSize:    DefaultStatsScanSize,
+		indexes:          make(map[string]bleve.Index),
+		writers:          make(map[string]*indexWriter),
+		closedIndexes:    make(map[string]struct{}),
+		memoryIndexes:    make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// 验证索引名称是否合法
+func IsValidIndexName(name string) bool {
+	// 验证索引名称只能包含字母、下划线和点，且不能是相对路径
+	return indexNameRegex.MatchString(name) && name != "." && name != ".."
+}
+
+// 索引数据目录
+func (e *Engine) indexPath(indexName string) string {
+	return filepath.Join(e.dataDir, indexName)
+}
+
+// 索引目录中的关闭标记文件
+func (e *Engine) closedMarkerPath(indexName string) string {
+	return filepath.Join(e.indexPath(indexName), closedMarkerFile)
+}
+
+// 磁盘上的索引是否已被关闭
+func (e *Engine) closedOnDisk(indexName string) bool {
+	_, err := os.Stat(e.closedMarkerPath(indexName))
+	return err == nil
+}
+
+// 将已打开的索引加入索引列表，调用方需持有写锁
+func (e *Engine) registerIndex(indexName string, index bleve.Index) error {
+	writer, err := newIndexWriter(index)
+	if err == nil {
+		err = e.restoreDynamicFields(index, writer.dynamic)
+	}
+	if err != nil {
+		index.Close()
+		return err
+	}
+	e.indexes[indexName] = index
+	e.writers[indexName] = writer
+	return nil
+}
+
+// 保存新建索引的动态映射和关键词提取设置后加入索引列表，调用方需持有写锁
+func (e *Engine) registerNewIndex(indexName string, index bleve.Index, dynamic *dynamicMapping, keywords []model.KeywordExtraction) error {
+	err := saveDynamicMapping(index, dynamic)
+	if err == nil {
+		err = saveKeywordExtractions(index, keywords)
+	}
+	if err != nil {
+		index.Close()
+		return err
+	}
+	return e.registerIndex(indexName, index)
+}
+
+// 索引的存储方式，调用方需持有锁
+func (e *Engine) storageOf(indexName string) string {
+	if _, memory := e.memoryIndexes[indexName]; memory || e.inMemory {
+		return model.StorageMemory
+	}
+	return model.StorageDisk
+}
+
+// 解析创建索引时指定的存储方式，为空时使用 Engine 的默认存储方式
+func (e *Engine) resolveStorage(storage string) (string, error) {
+	switch storage {
+	case "":
+		if e.inMemory {
+			return model.StorageMemory, nil
+		}
+		return model.StorageDisk, nil
+	case model.StorageMemory:
+		return storage, nil
+	case model.StorageDisk:
+		if e.inMemory {
+			return "", fmt.Errorf("内存模式下不支持磁盘存储的索引")
+		}
+		return storage, nil
+	default:
+		return "", fmt.Errorf("不支持的存储方式: %s", storage)
+	}
+}
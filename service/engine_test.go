@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"go-search/analysis/jieba"
+	"go-search/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnginesAreIsolated(t *testing.T) {
+	first, second := newTestEngine(t), newTestEngine(t)
+	for _, e := range []*Engine{first, second} {
+		if err := e.InitIndex("products", map[string]model.FieldMapping{"title": {Type: "jieba"}}, model.IndexOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{"title": "小米手机"}}
+	if _, err := first.AddDocument("products", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.GetDocument("products", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.GetDocument("products", "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second engine GetDocument err = %v, want ErrNotFound", err)
+	}
+
+	info, err := first.GetIndex("products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.DocCount != 1 {
+		t.Errorf("doc count = %d, want 1", info.DocCount)
+	}
+}
+
+func TestEngineDataDir(t *testing.T) {
+	dir := t.TempDir()
+
+	e := NewEngine(WithDataDir(dir), WithAnalyzer("zh", jieba.AnalyzerName))
+	if err := e.InitIndex("products", map[string]model.FieldMapping{"title": {Analyzer: "zh"}}, model.IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{"title": "我爱北京天安门"}}
+	if _, err := e.AddDocument("products", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新的 Engine 从同一目录加载已存在的索引
+	reopened := NewEngine(WithDataDir(dir))
+	if err := reopened.LoadAllIndexes(); err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.CloseAll()
+
+	result, err := reopened.QuerySearch("products", &model.Query{Term: &model.TermQuery{Field: "title", Value: "天安门"}}, model.SearchOptions{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("total = %d, want 1", result.Total)
+	}
+}
+
+func TestMemoryIndexOnDiskEngine(t *testing.T) {
+	dir := t.TempDir()
+	e := NewEngine(WithDataDir(dir))
+	defer e.CloseAll()
+
+	if err := e.InitIndex("cache", nil, model.IndexOptions{Storage: model.StorageMemory}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.InitIndex("products", nil, model.IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 内存索引不写入数据目录
+	if _, err := os.Stat(filepath.Join(dir, "cache")); !os.IsNotExist(err) {
+		t.Errorf("memory index created directory: %v", err)
+	}
+	infos, err := e.ListIndexes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Storage != model.StorageMemory || infos[1].Storage != model.StorageDisk {
+		t.Errorf("indexes = %+v", infos)
+	}
+
+	if err := e.CloseIndex("cache"); err == nil {
+		t.Error("closing a memory index should fail")
+	}
+	if err := e.DeleteIndex("cache"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.GetIndex("cache"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetIndex after delete err = %v", err)
+	}
+}
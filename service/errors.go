@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// 资源不存在，可通过 errors.Is(err, ErrNotFound) 判断
+	ErrNotFound = errors.New("资源不存在")
+	// 文档版本冲突或索引状态冲突(如已存在)，可通过 errors.Is(err, ErrConflict) 判断
+	ErrConflict = errors.New("版本冲突")
+	// 请求内容不合法，如 strict 索引中的文档包含未定义的字段，可通过 errors.Is(err, ErrInvalid) 判断
+	ErrInvalid = errors.New("请求不合法")
+)
+
+// 带分类的业务错误，errors.Is 按分类匹配
+type serviceError struct {
+	kind error
+	msg  string
+}
+
+func (e *serviceError) Error() string        { return e.msg }
+func (e *serviceError) Is(target error) bool { return target == e.kind }
+
+func indexNotFound(indexName string) error {
+	return &serviceError{ErrNotFound, fmt.Sprintf("索引 %s 不存在", indexName)}
+}
+
+func documentNotFound(indexName, docID string) error {
+	return &serviceError{ErrNotFound, fmt.Sprintf("文档 %s 在索引 %s 中不存在", docID, indexName)}
+}
+
+func indexConflict(indexName, state string) error {
+	return &serviceError{ErrConflict, fmt.Sprintf("索引 %s %s", indexName, state)}
+}
+
+func versionConflict(docID string, expected, current uint64) error {
+	return &serviceError{ErrConflict, fmt.Sprintf("文档 %s 版本冲突: 期望版本 %d, 当前版本 %d", docID, expected, current)}
+}
+
+func invalidRequest(format string, args ...interface{}) error {
+	return &serviceError{ErrInvalid, fmt.Sprintf(format, args...)}
+}
+
+// 将请求参数校验失败的错误标记为 ErrInvalid，已分类的错误保持不变
+func asInvalid(err error) error {
+	var se *serviceError
+	if err == nil || errors.As(err, &se) {
+		return err
+	}
+	return &serviceError{ErrInvalid, err.Error()}
+}
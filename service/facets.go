@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"go-search/model"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+const (
+	defaultTermsFacetSize = 10
+	// 自动生成日期区间的最大数量
+	maxDateHistogramBuckets = 1000
+)
+
+// 根据分面参数构建bleve的分面请求
+func buildFacets(facets map[string]model.FacetRequest) (bleve.FacetsRequest, error) {
+	result := make(bleve.FacetsRequest, len(facets))
+	for name, f := range facets {
+		if f.Field == "" {
+			return nil, fmt.Errorf("分面 %s 缺少字段", name)
+		}
+
+		var facet *bleve.FacetRequest
+		switch f.Type {
+		case "", "terms":
+			size := f.Size
+			if size <= 0 {
+				size = defaultTermsFacetSize
+			}
+			facet = bleve.NewFacetRequest(f.Field, size)
+		case "numeric_range":
+			if len(f.NumericRanges) == 0 {
+				return nil, fmt.Errorf("分面 %s 缺少数值区间", name)
+			}
+			facet = bleve.NewFacetRequest(f.Field, len(f.NumericRanges))
+			for _, r := range f.NumericRanges {
+				facet.AddNumericRange(r.Name, r.Min, r.Max)
+			}
+		case "date_range":
+			var err error
+			facet, err = buildDateRangeFacet(f)
+			if err != nil {
+				return nil, fmt.Errorf("分面 %s: %v", name, err)
+			}
+		default:
+			return nil, fmt.Errorf("不支持的分面类型: %s", f.Type)
+		}
+
+		if err := facet.Validate(); err != nil {
+			return nil, fmt.Errorf("分面 %s 不合法: %v", name, err)
+		}
+		result[name] = facet
+	}
+
+	return result, nil
+}
+
+// 构建日期区间分面，未指定区间时按 interval 自动生成等间隔区间
+func buildDateRangeFacet(f model.FacetRequest) (*bleve.FacetRequest, error) {
+	if len(f.DateRanges) > 0 {
+		facet := bleve.NewFacetRequest(f.Field, len(f.DateRanges))
+		for _, r := range f.DateRanges {
+			facet.AddDateTimeRangeString(r.Name, optionalString(r.Start), optionalString(r.End))
+		}
+		return facet, nil
+	}
+
+	if f.Interval == "" {
+		return nil, fmt.Errorf("缺少日期区间或 interval")
+	}
+	start, err := time.Parse(time.RFC3339, f.Start)
+	if err != nil {
+		return nil, fmt.Errorf("起始时间不合法: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, f.End)
+	if err != nil {
+		return nil, fmt.Errorf("结束时间不合法: %v", err)
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("起始时间必须早于结束时间")
+	}
+
+	var (
+		buckets []time.Time
+		layout  string
+	)
+	for t := start; t.Before(end); {
+		if len(buckets) >= maxDateHistogramBuckets {
+			return nil, fmt.Errorf("日期区间数量超过 %d", maxDateHistogramBuckets)
+		}
+		buckets = append(buckets, t)
+		switch f.Interval {
+		case "hour":
+			t, layout = t.Add(time.Hour), "2006-01-02T15"
+		case "day":
+			t, layout = t.AddDate(0, 0, 1), "2006-01-02"
+		case "week":
+			t, layout = t.AddDate(0, 0, 7), "2006-01-02"
+		case "month":
+			t, layout = t.AddDate(0, 1, 0), "2006-01"
+		case "year":
+			t, layout = t.AddDate(1, 0, 0), "2006"
+		default:
+			return nil, fmt.Errorf("不支持的时间间隔: %s", f.Interval)
+		}
+	}
+
+	facet := bleve.NewFacetRequest(f.Field, len(buckets))
+	for i, bucketStart := range buckets {
+		bucketEnd := end
+		if i+1 < len(buckets) {
+			bucketEnd = buckets[i+1]
+		}
+		facet.AddDateTimeRange(bucketStart.Format(layout), bucketStart, bucketEnd)
+	}
+	return facet, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"go-search/model"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestSearchFacets(t *testing.T) {
+	e := newTestEngine(t)
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping.AddFieldMappingsAt("category", bleve.NewKeywordFieldMapping())
+	indexMapping.DefaultMapping.AddFieldMappingsAt("created_at", bleve.NewDateTimeFieldMapping())
+	index := newTestIndex(t, e, "facets_test", indexMapping)
+
+	docs := map[string]map[string]interface{}{
+		"1": {"category": "phone", "price": 5999, "created_at": "2024-01-15T00:00:00Z"},
+		"2": {"category": "phone", "price": 6999, "created_at": "2024-02-10T00:00:00Z"},
+		"3": {"category": "tablet", "price": 3999, "created_at": "2024-02-20T00:00:00Z"},
+	}
+	for id, fields := range docs {
+		if err := index.Index(id, fields); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	min, max := 5000.0, 6000.0
+	opts := model.SearchOptions{
+		Page: 1,
+		Size: 1,
+		Facets: map[string]model.FacetRequest{
+			"categories": {Type: "terms", Field: "category"},
+			"prices": {Type: "numeric_range", Field: "price", NumericRanges: []model.NumericRangeBucket{
+				{Name: "cheap", Max: &min},
+				{Name: "mid", Min: &min, Max: &max},
+				{Name: "expensive", Min: &max},
+			}},
+			"months": {Type: "date_range", Field: "created_at", Interval: "month",
+				Start: "2024-01-01T00:00:00Z", End: "2024-04-01T00:00:00Z"},
+		},
+	}
+	result, err := e.Search("facets_test", "*", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categories := result.Facets["categories"].Terms.Terms()
+	if len(categories) != 2 || categories[0].Term != "phone" || categories[0].Count != 2 {
+		t.Errorf("categories = %v", categories)
+	}
+
+	prices := map[string]int{}
+	for _, r := range result.Facets["prices"].NumericRanges {
+		prices[r.Name] = r.Count
+	}
+	if prices["cheap"] != 1 || prices["mid"] != 1 || prices["expensive"] != 1 {
+		t.Errorf("prices = %v", prices)
+	}
+
+	months := map[string]int{}
+	for _, r := range result.Facets["months"].DateRanges {
+		months[r.Name] = r.Count
+	}
+	if months["2024-01"] != 1 || months["2024-02"] != 2 || months["2024-03"] != 0 {
+		t.Errorf("months = %v", months)
+	}
+}
+
+func TestBuildFacetsInvalid(t *testing.T) {
+	tests := []map[string]model.FacetRequest{
+		{"a": {Type: "terms"}},
+		{"a": {Type: "histogram", Field: "price"}},
+		{"a": {Type: "numeric_range", Field: "price"}},
+		{"a": {Type: "date_range", Field: "created_at", Interval: "day", Start: "2024-02-01T00:00:00Z", End: "2024-01-01T00:00:00Z"}},
+		{"a": {Type: "date_range", Field: "created_at", Interval: "century", Start: "2024-01-01T00:00:00Z", End: "2024-02-01T00:00:00Z"}},
+	}
+	for _, tt := range tests {
+		if _, err := buildFacets(tt); err == nil {
+			t.Errorf("buildFacets(%v) expected error", tt)
+		}
+	}
+}
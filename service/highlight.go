@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"go-search/model"
+	"strings"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	htmlFormatter "github.com/blevesearch/bleve/v2/search/highlight/format/html"
+	simpleFragmenter "github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	simpleHighlighter "github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+const (
+	highlighterType = "go_search"
+
+	defaultFragmentSize      = 100
+	defaultNumberOfFragments = 3
+	defaultPreTag            = "<mark>"
+	defaultPostTag           = "</mark>"
+
+	// 参数范围，高亮器按片段长度和数量注册到 bleve 的全局缓存且不会移除，需要限制组合数量
+	maxFragmentSize      = 1000
+	maxNumberOfFragments = 10
+	maxTagLength         = 64
+
+	// 高亮器统一使用私有区字符作为标签，搜索后再替换为请求的标签，标签不影响注册的高亮器数量
+	placeholderPreTag  = "\ue000"
+	placeholderPostTag = "\ue001"
+)
+
+// 保护高亮器的注册过程，避免并发重复定义
+var highlighterMu sync.Mutex
+
+func init() {
+	registry.RegisterHighlighter(highlighterType, newFragmentsHighlighter)
+}
+
+// 可配置片段数量的高亮器
+// bleve 每个字段固定只请求一个片段，这里忽略传入的数量，改用配置值
+type fragmentsHighlighter struct {
+	*simpleHighlighter.Highlighter
+	fragments int
+}
+
+func (h *fragmentsHighlighter) BestFragmentsInField(dm *search.DocumentMatch, doc index.Document, field string, num int) []string {
+	return h.Highlighter.BestFragmentsInField(dm, doc, field, h.fragments)
+}
+
+func newFragmentsHighlighter(config map[string]interface{}, cache *registry.Cache) (highlight.Highlighter, error) {
+	size, _ := config["fragment_size"].(int)
+	fragments, _ := config["number_of_fragments"].(int)
+	pre, _ := config["pre_tag"].(string)
+	post, _ := config["post_tag"].(string)
+
+	return &fragmentsHighlighter{
+		Highlighter: simpleHighlighter.NewHighlighter(
+			simpleFragmenter.NewFragmenter(size),
+			htmlFormatter.NewFragmentFormatter(pre, post),
+			simpleHighlighter.DefaultSeparator),
+		fragments: fragments,
+	}, nil
+}
+
+// 根据高亮参数构建bleve的高亮请求，返回的 Replacer 用于将片段中的占位标签替换为请求的标签
+// 相同参数的高亮器只注册一次，之后按名称复用
+func buildHighlight(h *model.HighlightRequest) (*bleve.HighlightRequest, *strings.Replacer, error) {
+	size := h.FragmentSize
+	if size <= 0 {
+		size = defaultFragmentSize
+	}
+	fragments := h.NumberOfFragments
+	if fragments <= 0 {
+		fragments = defaultNumberOfFragments
+	}
+	pre, post := h.PreTag, h.PostTag
+	if pre == "" && post == "" {
+		pre, post = defaultPreTag, defaultPostTag
+	}
+	if size > maxFragmentSize {
+		return nil, nil, fmt.Errorf("fragment_size 不能超过 %d", maxFragmentSize)
+	}
+	if fragments > maxNumberOfFragments {
+		return nil, nil, fmt.Errorf("number_of_fragments 不能超过 %d", maxNumberOfFragments)
+	}
+	if len(pre) > maxTagLength || len(post) > maxTagLength {
+		return nil, nil, fmt.Errorf("pre_tag 和 post_tag 不能超过 %d 字节", maxTagLength)
+	}
+
+	style := fmt.Sprintf("%s_%d_%d", highlighterType, size, fragments)
+
+	highlighterMu.Lock()
+	defer highlighterMu.Unlock()
+
+	if _, err := bleve.Config.Cache.HighlighterNamed(style); err != nil {
+		_, err = bleve.Config.Cache.DefineHighlighter(style, map[string]interface{}{
+			"type":                highlighterType,
+			"fragment_size":       size,
+			"number_of_fragments": fragments,
+			"pre_tag":             placeholderPreTag,
+			"post_tag":            placeholderPostTag,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("创建高亮器失败: %v", err)
+		}
+	}
+
+	request := bleve.NewHighlightWithStyle(style)
+	for _, field := range h.Fields {
+		request.AddField(field)
+	}
+	return request, strings.NewReplacer(placeholderPreTag, pre, placeholderPostTag, post), nil
+}
+
+// 将高亮片段中的占位标签替换为请求的标签
+func applyHighlightTags(hits search.DocumentMatchCollection, tags *strings.Replacer) {
+	for _, hit := range hits {
+		for _, fragments := range hit.Fragments {
+			for i, fragment := range fragments {
+				fragments[i] = tags.Replace(fragment)
+			}
+		}
+	}
+}
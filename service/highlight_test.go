@@ -0,0 +1,76 @@
+package service
+
+import (
+	"go-search/analysis/jieba"
+	"go-search/model"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestSearchHighlight(t *testing.T) {
+	e := newTestEngine(t)
+	indexMapping := bleve.NewIndexMapping()
+	nameMapping := bleve.NewTextFieldMapping()
+	nameMapping.Analyzer = jieba.AnalyzerName
+	indexMapping.DefaultMapping.AddFieldMappingsAt("name", nameMapping)
+	index := newTestIndex(t, e, "highlight_test", indexMapping)
+
+	if err := index.Index("1", map[string]interface{}{"name": "苹果手机星光色，支持全网通，官方正品保障，苹果官方旗舰店发货"}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := model.SearchOptions{
+		Page: 1,
+		Size: 10,
+		Highlight: &model.HighlightRequest{
+			Fields:            []string{"name"},
+			NumberOfFragments: 2,
+			FragmentSize:      8,
+			PreTag:            "<em>",
+			PostTag:           "</em>",
+		},
+	}
+	result, err := e.Search("highlight_test", "name:苹果", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Hits) != 1 {
+		t.Fatalf("hits = %d, want 1", len(result.Hits))
+	}
+	fragments := result.Hits[0].Fragments["name"]
+	if len(fragments) != 2 {
+		t.Fatalf("fragments = %v, want 2 fragments", fragments)
+	}
+	for _, fragment := range fragments {
+		if !strings.Contains(fragment, "<em>苹果</em>") {
+			t.Errorf("fragment %q does not contain highlighted term", fragment)
+		}
+	}
+}
+
+func TestBuildHighlightLimits(t *testing.T) {
+	// 不同的标签共用同一个高亮器
+	a, _, err := buildHighlight(&model.HighlightRequest{PreTag: "<em>", PostTag: "</em>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := buildHighlight(&model.HighlightRequest{PreTag: "<b>", PostTag: "</b>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *a.Style != *b.Style {
+		t.Errorf("styles = %q, %q, want the same highlighter", *a.Style, *b.Style)
+	}
+
+	for _, h := range []*model.HighlightRequest{
+		{FragmentSize: maxFragmentSize + 1},
+		{NumberOfFragments: maxNumberOfFragments + 1},
+		{PreTag: strings.Repeat("<b>", maxTagLength), PostTag: "</b>"},
+	} {
+		if _, _, err := buildHighlight(h); err == nil {
+			t.Errorf("buildHighlight(%+v) should fail", h)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-search/model"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+// 列出所有索引，包括已关闭的索引
+func (e *Engine) ListIndexes() ([]model.IndexInfo, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	infos := make([]model.IndexInfo, 0, len(e.indexes)+len(e.closedIndexes))
+	for name, index := range e.indexes {
+		docCount, err := index.DocCount()
+		if err != nil {
+			return nil, fmt.Errorf("获取索引 %s 文档数量失败: %v", name, err)
+		}
+		infos = append(infos, model.IndexInfo{
+			Name:     name,
+			Status:   model.IndexStatusOpen,
+			Storage:  e.storageOf(name),
+			DocCount: docCount,
+		})
+	}
+	for name := range e.closedIndexes {
+		infos = append(infos, model.IndexInfo{
+			Name:    name,
+			Status:  model.IndexStatusClosed,
+			Storage: model.StorageDisk,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// 获取索引信息，包含映射和文档数量
+func (e *Engine) GetIndex(indexName string) (*model.IndexInfo, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if _, closed := e.closedIndexes[indexName]; closed {
+		return &model.IndexInfo{
+			Name:    indexName,
+			Status:  model.IndexStatusClosed,
+			Storage: model.StorageDisk,
+		}, nil
+	}
+
+	index, exists := e.indexes[indexName]
+	if !exists {
+		return nil, indexNotFound(indexName)
+	}
+
+	docCount, err := index.DocCount()
+	if err != nil {
+		return nil, fmt.Errorf("获取文档数量失败: %v", err)
+	}
+
+	writer := e.writers[indexName]
+	dynamic := writer.dynamic
+	return &model.IndexInfo{
+		Name:             indexName,
+		Status:           model.IndexStatusOpen,
+		Storage:          e.storageOf(indexName),
+		DocCount:         docCount,
+		Mapping:          index.Mapping(),
+		Dynamic:          dynamic.Mode,
+		DynamicTemplates: dynamic.Templates,
+		Keywords:         writer.keywords,
+	}, nil
+}
+
+// 关闭索引，释放资源但保留磁盘数据，关闭状态记录在索引目录中，重启后仍保持关闭
+func (e *Engine) CloseIndex(indexName string) error {
+	// 写锁会等待持有读锁的请求全部结束
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	index, exists := e.indexes[indexName]
+	if !exists {
+		if _, closed := e.closedIndexes[indexName]; closed {
+			return indexConflict(indexName, "已关闭")
+		}
+		return indexNotFound(indexName)
+	}
+	// 内存索引关闭后数据即丢失，无法重新打开
+	if e.storageOf(indexName) == model.StorageMemory {
+		return invalidRequest("内存索引 %s 不支持关闭", indexName)
+	}
+
+	marker := e.closedMarkerPath(indexName)
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		return fmt.Errorf("记录索引关闭状态失败: %v", err)
+	}
+	if err := index.Close(); err != nil {
+		os.Remove(marker)
+		return fmt.Errorf("关闭索引失败: %v", err)
+	}
+	delete(e.indexes, indexName)
+	delete(e.writers, indexName)
+	e.closedIndexes[indexName] = struct{}{}
+	return nil
+}
+
+// 重新打开已关闭的索引
+func (e *Engine) OpenIndex(indexName string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.indexes[indexName]; exists {
+		return indexConflict(indexName, "已打开")
+	}
+	if _, closed := e.closedIndexes[indexName]; !closed {
+		return indexNotFound(indexName)
+	}
+
+	index, err := bleve.Open(e.indexPath(indexName))
+	if err != nil {
+		return fmt.Errorf("打开索引失败: %v", err)
+	}
+	if err := e.registerIndex(indexName, index); err != nil {
+		return err
+	}
+	if err := os.Remove(e.closedMarkerPath(indexName)); err != nil && !os.IsNotExist(err) {
+		index.Close()
+		delete(e.indexes, indexName)
+		delete(e.writers, indexName)
+		return fmt.Errorf("清除索引关闭状态失败: %v", err)
+	}
+	delete(e.closedIndexes, indexName)
+	return nil
+}
+
+// 删除索引，关闭后移除磁盘数据
+func (e *Engine) DeleteIndex(indexName string) error {
+	if !IsValidIndexName(indexName) {
+		return invalidRequest("索引名称不合法")
+	}
+
+	// 写锁会等待持有读锁的请求全部结束
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	index, exists := e.indexes[indexName]
+	_, closed := e.closedIndexes[indexName]
+	if !exists && !closed {
+		return indexNotFound(indexName)
+	}
+
+	// 确认删除的目录位于数据目录下，避免误删其他文件
+	memory := exists && e.storageOf(indexName) == model.StorageMemory
+	path := e.indexPath(indexName)
+	if !memory && filepath.Dir(path) != filepath.Clean(e.dataDir) {
+		return fmt.Errorf("索引路径不合法: %s", path)
+	}
+
+	if exists {
+		if err := index.Close(); err != nil {
+			return fmt.Errorf("关闭索引失败: %v", err)
+		}
+		delete(e.indexes, indexName)
+		delete(e.writers, indexName)
+		delete(e.memoryIndexes, indexName)
+	}
+	delete(e.closedIndexes, indexName)
+
+	// 内存索引没有磁盘数据
+	if memory {
+		return nil
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("删除索引目录失败: %v", err)
+	}
+	return nil
+}
+
+// 关闭所有已打开的索引，用于服务退出前将数据落盘
+// 单个索引关闭失败不影响其他索引，返回所有关闭失败的错误
+func (e *Engine) CloseAll() error {
+	// 写锁会等待持有读锁的请求全部结束
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	names := make([]string, 0, len(e.indexes))
+	for name := range e.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := e.indexes[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭索引 %s 失败: %v", name, err))
+		}
+		delete(e.indexes, name)
+		delete(e.writers, name)
+		delete(e.memoryIndexes, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"go-search/model"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestCloseAll(t *testing.T) {
+	e := newTestEngine(t)
+	first := newTestIndex(t, e, "close_all_first", bleve.NewIndexMapping())
+	second := newTestIndex(t, e, "close_all_second", bleve.NewIndexMapping())
+
+	if err := e.CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"close_all_first", "close_all_second"} {
+		if _, err := e.GetIndex(name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("e.GetIndex(%s) err = %v, want ErrNotFound", name, err)
+		}
+	}
+	// 已关闭的索引不能再写入
+	for _, index := range []bleve.Index{first, second} {
+		if err := index.Index("1", map[string]interface{}{"name": "test"}); err == nil {
+			t.Error("index still writable after CloseAll")
+		}
+	}
+}
+
+func TestIndexLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	e := NewEngine(WithDataDir(dir))
+	t.Cleanup(func() { e.CloseAll() })
+
+	if err := e.InitIndex("lifecycle", nil, model.IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{"name": "apple"}}
+	if _, err := e.AddDocument("lifecycle", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	search := func() error {
+		_, err := e.SearchByQuery("lifecycle", model.SearchQuery{Type: 1, Query: "apple"}, model.SearchOptions{Page: 1, Size: 10})
+		return err
+	}
+
+	if err := e.CloseIndex("lifecycle"); err != nil {
+		t.Fatal(err)
+	}
+	if err := search(); err == nil {
+		t.Error("search closed index err = nil")
+	}
+	if err := e.CloseIndex("lifecycle"); !errors.Is(err, ErrConflict) {
+		t.Errorf("close closed index err = %v, want ErrConflict", err)
+	}
+
+	// 重启后索引保持关闭
+	if err := e.CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	e = NewEngine(WithDataDir(dir))
+	t.Cleanup(func() { e.CloseAll() })
+	if err := e.LoadAllIndexes(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := e.GetIndex("lifecycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != model.IndexStatusClosed {
+		t.Errorf("status after restart = %s, want %s", info.Status, model.IndexStatusClosed)
+	}
+	if err := search(); err == nil {
+		t.Error("search closed index after restart err = nil")
+	}
+
+	if err := e.OpenIndex("lifecycle"); err != nil {
+		t.Fatal(err)
+	}
+	if err := search(); err != nil {
+		t.Errorf("search reopened index err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lifecycle", closedMarkerFile)); !os.IsNotExist(err) {
+		t.Errorf("closed marker still exists after open: %v", err)
+	}
+
+	if err := e.DeleteIndex("lifecycle"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lifecycle")); !os.IsNotExist(err) {
+		t.Errorf("index directory still exists after delete: %v", err)
+	}
+	if _, err := e.GetIndex("lifecycle"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get deleted index err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestIndexNameRejected(t *testing.T) {
+	dir := t.TempDir()
+	e := NewEngine(WithDataDir(filepath.Join(dir, "data")))
+	t.Cleanup(func() { e.CloseAll() })
+
+	// 数据目录之外的目录不能被删除
+	outside := filepath.Join(dir, "x")
+	if err := os.Mkdir(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"../x", "..", ".", "a/b", ""} {
+		if err := e.InitIndex(name, nil, model.IndexOptions{}); !errors.Is(err, ErrInvalid) {
+			t.Errorf("InitIndex(%q) err = %v, want ErrInvalid", name, err)
+		}
+		if err := e.DeleteIndex(name); !errors.Is(err, ErrInvalid) {
+			t.Errorf("DeleteIndex(%q) err = %v, want ErrInvalid", name, err)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("directory outside data dir removed: %v", err)
+	}
+}
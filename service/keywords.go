@@ -0,0 +1,172 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-search/analysis/jieba"
+	"go-search/model"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+// 关键词提取设置在索引内部存储中的键
+const keywordsKey = "_keywords"
+
+// 关键词提取的默认值
+const (
+	DefaultKeywordsField = "_keywords" // 保存关键词的字段
+	DefaultKeywordsTopK  = 5           // 提取的关键词数量
+	maxKeywordsTopK      = 100
+)
+
+// 校验创建索引时指定的关键词提取设置并补全默认值
+// 返回的字段映射中为保存关键词的字段添加了 keyword 映射
+func checkKeywordExtractions(extractions []model.KeywordExtraction, fields map[string]model.FieldMapping) ([]model.KeywordExtraction, map[string]model.FieldMapping, error) {
+	if len(extractions) == 0 {
+		return nil, fields, nil
+	}
+
+	checked := make([]model.KeywordExtraction, 0, len(extractions))
+	withTargets := make(map[string]model.FieldMapping, len(fields)+len(extractions))
+	maps.Copy(withTargets, fields)
+	for i, k := range extractions {
+		if k.Field == "" {
+			return nil, nil, fmt.Errorf("第 %d 个关键词提取设置必须指定 field", i+1)
+		}
+		if k.TargetField == "" {
+			k.TargetField = DefaultKeywordsField
+		}
+		if k.TopK == 0 {
+			k.TopK = DefaultKeywordsTopK
+		}
+		if k.TopK < 0 || k.TopK > maxKeywordsTopK {
+			return nil, nil, fmt.Errorf("第 %d 个关键词提取设置的 top_k 必须在 1 到 %d 之间", i+1, maxKeywordsTopK)
+		}
+		if k.TargetField == k.Field {
+			return nil, nil, fmt.Errorf("第 %d 个关键词提取设置的 target_field 不能与 field 相同", i+1)
+		}
+		if field, ok := withTargets[k.TargetField]; !ok {
+			withTargets[k.TargetField] = model.FieldMapping{Type: model.FieldTypeKeyword}
+		} else if field.Type != model.FieldTypeKeyword {
+			return nil, nil, fmt.Errorf("关键词字段 %s 必须是 keyword 类型", k.TargetField)
+		}
+		checked = append(checked, k)
+	}
+	return checked, withTargets, nil
+}
+
+// 读取索引的关键词提取设置
+func loadKeywordExtractions(idx bleve.Index) ([]model.KeywordExtraction, error) {
+	data, err := idx.GetInternal([]byte(keywordsKey))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var extractions []model.KeywordExtraction
+	if err := json.Unmarshal(data, &extractions); err != nil {
+		return nil, fmt.Errorf("解析关键词提取设置失败: %v", err)
+	}
+	return extractions, nil
+}
+
+func saveKeywordExtractions(idx bleve.Index, extractions []model.KeywordExtraction) error {
+	if len(extractions) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(extractions)
+	if err != nil {
+		return err
+	}
+	if err := idx.SetInternal([]byte(keywordsKey), data); err != nil {
+		return fmt.Errorf("保存关键词提取设置失败: %v", err)
+	}
+	return nil
+}
+
+// 建立索引使用的字段，在文档字段的基础上加入提取的关键词，不修改文档的原始内容
+// 保存关键词的字段总是由提取结果替换，多个设置使用相同的字段时合并去重
+func (w *indexWriter) indexedFields(fields map[string]interface{}) (map[string]interface{}, error) {
+	if len(w.keywords) == 0 {
+		return fields, nil
+	}
+
+	targets := make(map[string][]string)
+	for _, k := range w.keywords {
+		words := targets[k.TargetField]
+		if text := fieldText(fields, k.Field); text != "" {
+			keywords, err := jieba.ExtractKeywords(text, k.TopK)
+			if err != nil {
+				return nil, fmt.Errorf("提取关键词失败: %v", err)
+			}
+			for _, keyword := range keywords {
+				if !slices.Contains(words, keyword.Word) {
+					words = append(words, keyword.Word)
+				}
+			}
+		}
+		targets[k.TargetField] = words
+	}
+
+	indexed := maps.Clone(fields)
+	for target, words := range targets {
+		if len(words) == 0 {
+			delete(indexed, target)
+		} else {
+			indexed[target] = words
+		}
+	}
+	return indexed, nil
+}
+
+// 字段中的文本，字段名可以是嵌套对象的路径，数组中的字符串以换行连接
+func fieldText(fields map[string]interface{}, path string) string {
+	value, ok := fields[path]
+	if !ok {
+		var current interface{} = fields
+		for _, segment := range strings.Split(path, ".") {
+			object, isObject := current.(map[string]interface{})
+			if !isObject {
+				return ""
+			}
+			current = object[segment]
+		}
+		value = current
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		texts := make([]string, 0, len(v))
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	default:
+		return ""
+	}
+}
+
+// 按 TF-IDF 提取文本中权重最高的关键词，topK 为0时使用默认数量
+func (e *Engine) ExtractKeywords(text string, topK int) ([]model.Keyword, error) {
+	if topK == 0 {
+		topK = DefaultKeywordsTopK
+	}
+	if topK < 0 || topK > maxKeywordsTopK {
+		return nil, invalidRequest("top_k 必须在 1 到 %d 之间", maxKeywordsTopK)
+	}
+
+	keywords, err := jieba.ExtractKeywords(text, topK)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.Keyword, 0, len(keywords))
+	for _, k := range keywords {
+		result = append(result, model.Keyword{Word: k.Word, Weight: k.Weight})
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"go-search/model"
+	"testing"
+)
+
+func TestKeywordExtraction(t *testing.T) {
+	e := NewEngine(WithDataDir(t.TempDir()))
+	defer e.CloseAll()
+
+	fields := map[string]model.FieldMapping{"name": {Type: "jieba"}, "description": {Type: "jieba"}}
+	opts := model.IndexOptions{Keywords: []model.KeywordExtraction{{Field: "description", TopK: 3}}}
+	if err := e.InitIndex("keywords_test", fields, opts); err != nil {
+		t.Fatal(err)
+	}
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{
+		"name":        "小米14",
+		"description": "这款手机采用骁龙处理器，支持NFC和无线充电，电池续航表现出色，手机拍照效果也很好。",
+	}}
+	if _, err := e.AddDocument("keywords_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	keyword := func(value string) *model.Query {
+		return &model.Query{Term: &model.TermQuery{Field: DefaultKeywordsField, Value: value}}
+	}
+	if n := countMatches(t, e, "keywords_test", keyword("骁龙")); n != 1 {
+		t.Errorf("keyword total = %d, want 1", n)
+	}
+	if n := countMatches(t, e, "keywords_test", keyword("充电")); n != 0 {
+		t.Errorf("keyword outside top_k total = %d, want 0", n)
+	}
+
+	// 关键词不写入原始内容，更新文档后重新提取
+	index, w := e.indexes["keywords_test"], e.writers["keywords_test"]
+	source, err := loadDocument(index, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := source.Fields[DefaultKeywordsField]; ok {
+		t.Errorf("source = %v", source.Fields)
+	}
+	update := model.Document{ID: "1", Fields: map[string]interface{}{"description": "蓝牙耳机降噪效果出色，耳机佩戴舒适"}}
+	if _, err := e.UpdateDocument("keywords_test", update, model.UpdateModeMerge, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := countMatches(t, e, "keywords_test", keyword("骁龙")); n != 0 {
+		t.Errorf("stale keyword total = %d, want 0", n)
+	}
+	if n := countMatches(t, e, "keywords_test", keyword("耳机")); n != 1 {
+		t.Errorf("updated keyword total = %d, want 1", n)
+	}
+	if len(w.keywords) != 1 || w.keywords[0].TargetField != DefaultKeywordsField {
+		t.Errorf("keywords = %+v", w.keywords)
+	}
+
+	// 设置保存在索引中，重新打开后仍然有效
+	if err := e.CloseIndex("keywords_test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.OpenIndex("keywords_test"); err != nil {
+		t.Fatal(err)
+	}
+	doc.ID = "2"
+	if _, err := e.AddDocument("keywords_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := countMatches(t, e, "keywords_test", keyword("骁龙")); n != 1 {
+		t.Errorf("keyword after reopen total = %d, want 1", n)
+	}
+}
+
+func TestKeywordExtractionInvalid(t *testing.T) {
+	e := newTestEngine(t)
+
+	tests := map[string][]model.KeywordExtraction{
+		"missing field":    {{TargetField: "tags"}},
+		"bad top_k":        {{Field: "description", TopK: -1}},
+		"same field":       {{Field: "description", TargetField: "description"}},
+		"non keyword type": {{Field: "description", TargetField: "name"}},
+	}
+	fields := map[string]model.FieldMapping{"name": {Type: "jieba"}}
+	for name, keywords := range tests {
+		if err := e.InitIndex("invalid_test", fields, model.IndexOptions{Keywords: keywords}); err == nil {
+			t.Errorf("%s: InitIndex succeeded", name)
+		}
+	}
+
+	if _, err := e.ExtractKeywords("蓝牙耳机", 1000); err == nil {
+		t.Error("ExtractKeywords accepted top_k 1000")
+	}
+}
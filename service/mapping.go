@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"go-search/model"
+	"reflect"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/datetime/flexible"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// 根据字段配置及自定义分析器构建索引映射，dynamic 为 false 时不为未定义的字段建立索引
+func (e *Engine) buildIndexMapping(fields map[string]model.FieldMapping, analysis *model.Analysis, dynamic bool) (*mapping.IndexMappingImpl, error) {
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping.Dynamic = dynamic
+
+	if err := e.addAnalysis(indexMapping, analysis); err != nil {
+		return nil, err
+	}
+
+	if err := e.addFieldMappings(indexMapping, indexMapping.DefaultMapping, "", fields); err != nil {
+		return nil, err
+	}
+
+	// 检查字段引用的分析器、日期解析器等是否已注册，避免创建索引时才失败
+	if err := indexMapping.Validate(); err != nil {
+		return nil, fmt.Errorf("索引映射不合法: %v", err)
+	}
+
+	return indexMapping, nil
+}
+
+// 将字段配置加入文档映射，prefix 为文档映射对应的对象路径
+// 字段名中的点号及 object 类型的字段都会构建为子文档映射
+func (e *Engine) addFieldMappings(indexMapping *mapping.IndexMappingImpl, docMapping *mapping.DocumentMapping, prefix string, fields map[string]model.FieldMapping) error {
+	for name, field := range fields {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		segments := strings.Split(name, ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return fmt.Errorf("字段名 %s 不合法", path)
+			}
+		}
+
+		// 逐级找到字段所在的对象
+		parent := docMapping
+		for i, segment := range segments[:len(segments)-1] {
+			var err error
+			parent, err = subDocumentMapping(parent, segment)
+			if err != nil {
+				return fmt.Errorf("字段 %s 配置不合法: %v", strings.Join(segments[:i+1], "."), err)
+			}
+		}
+		last := segments[len(segments)-1]
+
+		if field.Type == model.FieldTypeObject {
+			if err := checkObjectField(field); err != nil {
+				return fmt.Errorf("字段 %s 配置不合法: %v", path, err)
+			}
+			sub, err := subDocumentMapping(parent, last)
+			if err != nil {
+				return fmt.Errorf("字段 %s 配置不合法: %v", path, err)
+			}
+			if err := e.addFieldMappings(indexMapping, sub, path, field.Properties); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(field.Properties) > 0 {
+			return fmt.Errorf("字段 %s 配置不合法: 只有 object 类型的字段可以指定 properties", path)
+		}
+		if existing, ok := parent.Properties[last]; ok {
+			if len(existing.Properties) > 0 {
+				return fmt.Errorf("字段 %s 配置不合法: 已定义为对象", path)
+			}
+			return fmt.Errorf("字段 %s 重复定义", path)
+		}
+		fieldMapping, err := e.buildFieldMapping(indexMapping, path, field)
+		if err != nil {
+			return fmt.Errorf("字段 %s 配置不合法: %v", path, err)
+		}
+		parent.AddFieldMappingsAt(last, fieldMapping)
+	}
+
+	return nil
+}
+
+// 获取或创建对象字段的子文档映射
+func subDocumentMapping(parent *mapping.DocumentMapping, name string) (*mapping.DocumentMapping, error) {
+	if sub, ok := parent.Properties[name]; ok {
+		if len(sub.Fields) > 0 {
+			return nil, fmt.Errorf("已定义为普通字段，不能作为对象")
+		}
+		return sub, nil
+	}
+
+	// 子对象与上级对象使用相同的 dynamic 设置
+	sub := bleve.NewDocumentMapping()
+	sub.Dynamic = parent.Dynamic
+	parent.AddSubDocumentMapping(name, sub)
+	return sub, nil
+}
+
+// object 类型的字段只能指定 properties
+func checkObjectField(field model.FieldMapping) error {
+	if len(field.Properties) == 0 {
+		return fmt.Errorf("object 类型的字段必须指定 properties")
+	}
+	field.Type, field.Properties = "", nil
+	if !reflect.DeepEqual(field, model.FieldMapping{}) {
+		return fmt.Errorf("object 类型的字段只能指定 properties")
+	}
+	return nil
+}
+
+// 构建单个字段的映射
+func (e *Engine) buildFieldMapping(indexMapping *mapping.IndexMappingImpl, fieldName string, field model.FieldMapping) (*mapping.FieldMapping, error) {
+	if field.Analyzer != "" && field.Type != "" && field.Type != model.FieldTypeText {
+		return nil, fmt.Errorf("只有 text 类型的字段可以指定分词器")
+	}
+	if (field.DateFormat != "" || len(field.DateLayouts) > 0) && field.Type != model.FieldTypeDate {
+		return nil, fmt.Errorf("只有 date 类型的字段可以指定日期格式")
+	}
+
+	var fieldMapping *mapping.FieldMapping
+	switch field.Type {
+	case "", model.FieldTypeText:
+		fieldMapping = bleve.NewTextFieldMapping()
+		if field.Analyzer != "" {
+			fieldMapping.Analyzer = e.analyzerName(field.Analyzer)
+		}
+	case model.FieldTypeKeyword:
+		fieldMapping = bleve.NewKeywordFieldMapping()
+	case model.FieldTypeNumber:
+		fieldMapping = bleve.NewNumericFieldMapping()
+	case model.FieldTypeDate:
+		fieldMapping = bleve.NewDateTimeFieldMapping()
+		dateFormat, err := dateTimeParser(indexMapping, fieldName, field)
+		if err != nil {
+			return nil, err
+		}
+		fieldMapping.DateFormat = dateFormat
+	case model.FieldTypeBoolean:
+		fieldMapping = bleve.NewBooleanFieldMapping()
+	case model.FieldTypeGeoPoint:
+		fieldMapping = bleve.NewGeoPointFieldMapping()
+	case model.FieldTypeGeoShape:
+		fieldMapping = bleve.NewGeoShapeFieldMapping()
+	case model.FieldTypeIP:
+		fieldMapping = bleve.NewIPFieldMapping()
+	case model.FieldTypeVector:
+		var err error
+		fieldMapping, err = newVectorFieldMapping(field)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		// 兼容简写形式，类型为分词器名称(如 jieba)或自定义分析器名称时作为使用该分词器的文本字段，其他情况使用默认分词器
+		fieldMapping = bleve.NewTextFieldMapping()
+		if analyzer, ok := e.analyzers[field.Type]; ok {
+			fieldMapping.Analyzer = analyzer
+		} else if _, ok := indexMapping.CustomAnalysis.Analyzers[field.Type]; ok {
+			fieldMapping.Analyzer = field.Type
+		}
+	}
+
+	if field.Store != nil {
+		fieldMapping.Store = *field.Store
+	}
+	if field.Index != nil {
+		fieldMapping.Index = *field.Index
+	}
+	if field.IncludeInAll != nil {
+		fieldMapping.IncludeInAll = *field.IncludeInAll
+	}
+	if field.DocValues != nil {
+		fieldMapping.DocValues = *field.DocValues
+	}
+	if field.IncludeTermVectors != nil {
+		fieldMapping.IncludeTermVectors = *field.IncludeTermVectors
+	}
+
+	return fieldMapping, nil
+}
+
+// 分词器名称，优先使用通过 WithAnalyzer 注册的名称，否则作为 bleve 分析器名称
+func (e *Engine) analyzerName(name string) string {
+	if analyzer, ok := e.analyzers[name]; ok {
+		return analyzer
+	}
+	return name
+}
+
+// 日期字段使用的解析器名称，指定 date_layouts 时为该字段注册自定义解析器
+func dateTimeParser(indexMapping *mapping.IndexMappingImpl, fieldName string, field model.FieldMapping) (string, error) {
+	if len(field.DateLayouts) == 0 {
+		return field.DateFormat, nil
+	}
+	if field.DateFormat != "" {
+		return "", fmt.Errorf("date_format 和 date_layouts 不能同时指定")
+	}
+
+	layouts := make([]interface{}, len(field.DateLayouts))
+	for i, layout := range field.DateLayouts {
+		layouts[i] = layout
+	}
+	name := "date_layouts_" + fieldName
+	err := indexMapping.AddCustomDateTimeParser(name, map[string]interface{}{
+		"type":    flexible.Name,
+		"layouts": layouts,
+	})
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
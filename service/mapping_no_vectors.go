@@ -0,0 +1,16 @@
+//go:build !vectors
+// +build !vectors
+
+package service
+
+import (
+	"fmt"
+	"go-search/model"
+
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// 未使用 vectors 标签编译时不支持向量字段
+func newVectorFieldMapping(field model.FieldMapping) (*mapping.FieldMapping, error) {
+	return nil, fmt.Errorf("vector 字段需要使用 -tags vectors 编译")
+}
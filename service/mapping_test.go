@@ -0,0 +1,204 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"go-search/model"
+	"testing"
+)
+
+func TestFieldTypes(t *testing.T) {
+	e := newTestEngine(t)
+
+	var fields map[string]model.FieldMapping
+	err := json.Unmarshal([]byte(`{
+		"title": "jieba",
+		"released": {"type": "date", "date_layouts": ["2006/01/02"]},
+		"available": {"type": "boolean"},
+		"location": {"type": "geopoint"},
+		"server_ip": {"type": "ip"},
+		"sku": {"type": "keyword", "store": false, "include_in_all": false, "include_term_vectors": false}
+	}`), &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.InitIndex("types_test", fields, model.IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := model.Document{ID: "1", Fields: map[string]interface{}{
+		"title":     "小米手机",
+		"released":  "2024/03/01",
+		"available": true,
+		"location":  map[string]interface{}{"lat": 39.9, "lon": 116.4},
+		"server_ip": "192.168.1.10",
+		"sku":       "XM-14",
+	}}
+	if _, err := e.AddDocument("types_test", doc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 使用自定义格式解析的日期可以按范围查询
+	dateQuery := &model.Query{DateRange: &model.DateRangeQuery{Field: "released", GTE: "2024-02-01T00:00:00Z", LT: "2024-04-01T00:00:00Z"}}
+	result, err := e.QuerySearch("types_test", dateQuery, model.SearchOptions{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("date range total = %d, want 1", result.Total)
+	}
+
+	// 不保存原始值的字段仍可搜索，但获取文档时不返回
+	skuQuery := &model.Query{Term: &model.TermQuery{Field: "sku", Value: "XM-14"}}
+	result, err = e.QuerySearch("types_test", skuQuery, model.SearchOptions{Page: 1, Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 {
+		t.Errorf("sku total = %d, want 1", result.Total)
+	}
+	stored, err := e.GetDocument("types_test", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Fields["available"] != true || stored.Fields["server_ip"] != "192.168.1.10" {
+		t.Errorf("stored fields = %+v", stored.Fields)
+	}
+
+	info, err := e.GetIndex("types_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(info.Mapping)
+	var indexMapping struct {
+		DefaultMapping struct {
+			Properties map[string]struct {
+				Fields []map[string]interface{} `json:"fields"`
+			} `json:"properties"`
+		} `json:"default_mapping"`
+	}
+	if err := json.Unmarshal(data, &indexMapping); err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := map[string]string{"released": "datetime", "available": "boolean", "location": "geopoint", "server_ip": "IP", "sku": "text"}
+	for name, want := range wantTypes {
+		got := indexMapping.DefaultMapping.Properties[name].Fields
+		if len(got) != 1 || got[0]["type"] != want {
+			t.Errorf("field %s mapping = %v, want type %s", name, got, want)
+		}
+	}
+	if sku := indexMapping.DefaultMapping.Properties["sku"].Fields[0]; sku["store"] != nil || sku["include_in_all"] != nil {
+		t.Errorf("sku options not applied: %v", sku)
+	}
+}
+
+func TestFieldMappingInvalid(t *testing.T) {
+	e := newTestEngine(t)
+
+	tests := map[string]model.FieldMapping{
+		"analyzer on keyword":   {Type: model.FieldTypeKeyword, Analyzer: "jieba"},
+		"layouts on text":       {DateLayouts: []string{"2006"}},
+		"format and layouts":    {Type: model.FieldTypeDate, DateFormat: "dateTimeOptional", DateLayouts: []string{"2006"}},
+		"vector without dims":   {Type: model.FieldTypeVector},
+		"unregistered analyzer": {Analyzer: "no_such_analyzer"},
+		"undefined analyzer":    {Type: model.FieldTypeText, Analyzer: "nope"},
+		"unregistered format":   {Type: model.FieldTypeDate, DateFormat: "nope"},
+	}
+	for name, field := range tests {
+		if err := e.InitIndex("invalid_test", map[string]model.FieldMapping{"f": field}, model.IndexOptions{}); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+			e.DeleteIndex("invalid_test")
+		}
+	}
+
+	var field model.FieldMapping
+	if err := json.Unmarshal([]byte(`{"type": "keyword", "stored": true}`), &field); err == nil {
+		t.Error("unknown field option should be rejected")
+	}
+}
+
+func TestNestedFieldMappings(t *testing.T) {
+	e := newTestEngine(t)
+
+	var fields map[string]model.FieldMapping
+	err := json.Unmarshal([]byte(`{
+		"brand.name": "jieba",
+		"specs": {"type": "object", "properties": {
+			"color": "keyword",
+			"weight": "number",
+			"size.width": "number"
+		}}
+	}`), &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.InitIndex("nested_test", fields, model.IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []model.Document{
+		{ID: "1", Fields: map[string]interface{}{
+			"brand": map[string]interface{}{"name": "小米科技"},
+			"specs": map[string]interface{}{"color": "Deep Blue", "weight": 190.0, "size": map[string]interface{}{"width": 71.5}},
+		}},
+		{ID: "2", Fields: map[string]interface{}{
+			"brand": map[string]interface{}{"name": "华为技术"},
+			"specs": map[string]interface{}{"color": "Deep Blue", "weight": 170.0, "size": map[string]interface{}{"width": 70.0}},
+		}},
+		{ID: "3", Fields: map[string]interface{}{
+			"brand": map[string]interface{}{"name": "小米科技"},
+			"specs": map[string]interface{}{"color": "White", "weight": 180.0, "size": map[string]interface{}{"width": 72.0}},
+		}},
+	}
+	for _, doc := range docs {
+		if _, err := e.AddDocument("nested_test", doc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// keyword 类型的嵌套字段不分词，按子字段排序
+	colorQuery := &model.Query{Term: &model.TermQuery{Field: "specs.color", Value: "Deep Blue"}}
+	result, err := e.QuerySearch("nested_test", colorQuery, model.SearchOptions{Page: 1, Size: 10, SortBy: "specs.weight"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || result.Hits[0].ID != "2" || result.Hits[1].ID != "1" {
+		t.Errorf("specs.color hits = %v", hitIDs(result.Hits))
+	}
+
+	min := 71.0
+	query := &model.Query{Bool: &model.BoolQuery{
+		Must:   []model.Query{{Match: &model.MatchQuery{Field: "brand.name", Query: "小米"}}},
+		Filter: []model.Query{{NumericRange: &model.NumericRangeQuery{Field: "specs.size.width", GTE: &min}}},
+	}}
+	result, err = e.QuerySearch("nested_test", query, model.SearchOptions{Page: 1, Size: 10, SortBy: "-specs.weight"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || result.Hits[0].ID != "1" || result.Hits[1].ID != "3" {
+		t.Errorf("brand.name hits = %v", hitIDs(result.Hits))
+	}
+}
+
+func TestNestedFieldMappingsInvalid(t *testing.T) {
+	e := newTestEngine(t)
+
+	tests := map[string]string{
+		"field and object":   `{"specs": "keyword", "specs.color": "keyword"}`,
+		"duplicate field":    `{"specs.color": "keyword", "specs": {"type": "object", "properties": {"color": "text"}}}`,
+		"empty segment":      `{"specs..color": "keyword"}`,
+		"object without sub": `{"specs": {"type": "object"}}`,
+		"object with option": `{"specs": {"type": "object", "store": false, "properties": {"color": "keyword"}}}`,
+		"properties on text": `{"specs": {"type": "text", "properties": {"color": "keyword"}}}`,
+	}
+	for name, definition := range tests {
+		var fields map[string]model.FieldMapping
+		if err := json.Unmarshal([]byte(definition), &fields); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.InitIndex("nested_invalid", fields, model.IndexOptions{}); err == nil {
+			t.Errorf("%s: expected error", name)
+			e.DeleteIndex("nested_invalid")
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build vectors
+// +build vectors
+
+package service
+
+import (
+	"fmt"
+	"go-search/model"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// 构建向量字段映射
+func newVectorFieldMapping(field model.FieldMapping) (*mapping.FieldMapping, error) {
+	if field.Dims <= 0 {
+		return nil, fmt.Errorf("vector 字段必须指定 dims")
+	}
+
+	fieldMapping := bleve.NewVectorFieldMapping()
+	fieldMapping.Dims = field.Dims
+	if field.Similarity != "" {
+		fieldMapping.Similarity = field.Similarity
+	}
+	if field.VectorIndexOptimizedFor != "" {
+		fieldMapping.VectorIndexOptimizedFor = field.VectorIndexOptimizedFor
+	}
+	return fieldMapping, nil
+}
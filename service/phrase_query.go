@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/blevesearch/bleve/v2/search/searcher"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// 允许词之间有间隔的短语查询，bleve 的短语查询不支持 slop
+// 查询文本按字段的分析器分词，文档中的词必须按查询中的顺序出现，
+// 与查询相比多出的间隔词数之和不超过 slop
+type slopPhraseQuery struct {
+	phrase   string
+	field    string
+	analyzer string
+	slop     int
+	boost    *query.Boost
+}
+
+func newSlopPhraseQuery(phrase string, slop int) *slopPhraseQuery {
+	return &slopPhraseQuery{phrase: phrase, slop: slop}
+}
+
+func (q *slopPhraseQuery) SetBoost(b float64) {
+	boost := query.Boost(b)
+	q.boost = &boost
+}
+
+func (q *slopPhraseQuery) Boost() float64 {
+	return q.boost.Value()
+}
+
+func (q *slopPhraseQuery) SetField(f string) {
+	q.field = f
+}
+
+func (q *slopPhraseQuery) Field() string {
+	return q.field
+}
+
+func (q *slopPhraseQuery) Searcher(ctx context.Context, i index.IndexReader, m mapping.IndexMapping, options search.SearcherOptions) (search.Searcher, error) {
+	field := q.field
+	if field == "" {
+		field = m.DefaultSearchField()
+	}
+	analyzerName := q.analyzer
+	if analyzerName == "" {
+		analyzerName = m.AnalyzerNameForPath(field)
+	}
+	analyzer := m.AnalyzerNamed(analyzerName)
+	if analyzer == nil {
+		return nil, fmt.Errorf("分析器 %s 不存在", analyzerName)
+	}
+
+	parts := phraseParts(analyzer.Analyze([]byte(q.phrase)))
+	if len(parts) == 0 {
+		return query.NewMatchNoneQuery().Searcher(ctx, i, m, options)
+	}
+
+	// 先找出包含所有词的文档，再检查词的位置
+	options.IncludeTermVectors = true
+	searchers := make([]search.Searcher, 0, len(parts))
+	closeAll := func() {
+		for _, s := range searchers {
+			_ = s.Close()
+		}
+	}
+	for _, part := range parts {
+		s, err := partSearcher(ctx, i, part.terms, field, q.boost.Value(), options)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		searchers = append(searchers, s)
+	}
+	conjunction, err := searcher.NewConjunctionSearcher(ctx, i, searchers, options)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return &slopPhraseSearcher{Searcher: conjunction, parts: parts, slop: q.slop}, nil
+}
+
+// 短语中一个位置上的词，同义词等位于相同位置的词匹配任意一个即可
+type phrasePart struct {
+	terms  []string
+	offset uint64 // 相对于短语中第一个词的位置
+}
+
+// 按位置合并查询文本的词元，被过滤掉的位置(如停用词)保留为间隔
+func phraseParts(tokens analysis.TokenStream) []phrasePart {
+	var parts []phrasePart
+	first := 0
+	for _, token := range tokens {
+		if first == 0 || token.Position < first {
+			first = token.Position
+		}
+	}
+	for _, token := range tokens {
+		offset := uint64(token.Position - first)
+		i := slices.IndexFunc(parts, func(p phrasePart) bool { return p.offset == offset })
+		if i < 0 {
+			parts = append(parts, phrasePart{offset: offset})
+			i = len(parts) - 1
+		}
+		if term := string(token.Term); !slices.Contains(parts[i].terms, term) {
+			parts[i].terms = append(parts[i].terms, term)
+		}
+	}
+	slices.SortFunc(parts, func(a, b phrasePart) int { return int(a.offset) - int(b.offset) })
+	return parts
+}
+
+// 匹配短语中一个位置的词的搜索器
+func partSearcher(ctx context.Context, i index.IndexReader, terms []string, field string, boost float64, options search.SearcherOptions) (search.Searcher, error) {
+	if len(terms) == 1 {
+		return searcher.NewTermSearcher(ctx, i, terms[0], field, boost, options)
+	}
+	termSearchers := make([]search.Searcher, 0, len(terms))
+	for _, term := range terms {
+		s, err := searcher.NewTermSearcher(ctx, i, term, field, boost, options)
+		if err != nil {
+			for _, s := range termSearchers {
+				_ = s.Close()
+			}
+			return nil, err
+		}
+		termSearchers = append(termSearchers, s)
+	}
+	return searcher.NewDisjunctionSearcher(ctx, i, termSearchers, 1, options)
+}
+
+// 在包含所有词的文档中筛选出位置满足短语要求的文档
+type slopPhraseSearcher struct {
+	search.Searcher
+	parts []phrasePart
+	slop  int
+}
+
+func (s *slopPhraseSearcher) Next(ctx *search.SearchContext) (*search.DocumentMatch, error) {
+	for {
+		match, err := s.Searcher.Next(ctx)
+		if err != nil || match == nil || s.matches(match) {
+			return match, err
+		}
+		ctx.DocumentMatchPool.Put(match)
+	}
+}
+
+func (s *slopPhraseSearcher) Advance(ctx *search.SearchContext, ID index.IndexInternalID) (*search.DocumentMatch, error) {
+	match, err := s.Searcher.Advance(ctx, ID)
+	if err != nil || match == nil || s.matches(match) {
+		return match, err
+	}
+	ctx.DocumentMatchPool.Put(match)
+	return s.Next(ctx)
+}
+
+// 文档中是否存在满足要求的短语，数组字段的不同元素分别匹配
+func (s *slopPhraseSearcher) matches(match *search.DocumentMatch) bool {
+	elements := make(map[string]map[string][]uint64)
+	for _, ftl := range match.FieldTermLocations {
+		key := fmt.Sprint(ftl.Location.ArrayPositions)
+		if elements[key] == nil {
+			elements[key] = make(map[string][]uint64)
+		}
+		elements[key][ftl.Term] = append(elements[key][ftl.Term], ftl.Location.Pos)
+	}
+
+	for _, positions := range elements {
+		for _, term := range s.parts[0].terms {
+			for _, pos := range positions[term] {
+				if matchPhraseParts(s.parts[1:], positions, pos, s.parts[0].offset, s.slop) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// 从上一个词的位置 prev 开始匹配剩余的词，每个词与上一个词的间隔比查询中多出的部分从 slop 中扣除
+func matchPhraseParts(parts []phrasePart, positions map[string][]uint64, prev, prevOffset uint64, slop int) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	expected := prev + parts[0].offset - prevOffset
+	for _, term := range parts[0].terms {
+		for _, pos := range positions[term] {
+			if pos < expected || int(pos-expected) > slop {
+				continue
+			}
+			if matchPhraseParts(parts[1:], positions, pos, parts[0].offset, slop-int(pos-expected)) {
+				return true
+			}
+		}
+	}
+	return false
+}
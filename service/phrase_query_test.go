@@ -0,0 +1,50 @@
+package service
+
+import (
+	"go-search/model"
+	"testing"
+)
+
+func TestJiebaPhraseQuery(t *testing.T) {
+	e := newTestEngine(t)
+	if err := e.InitIndex("phrase_test", map[string]model.FieldMapping{"title": {Type: "jieba"}}, model.IndexOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for id, title := range map[string]string{
+		"1": "南京市长江大桥",
+		"2": "长江大桥位于南京市",
+		"3": "小米 NFC手机 星光色",
+		"4": "小米的手机",
+	} {
+		doc := model.Document{ID: id, Fields: map[string]interface{}{"title": title}}
+		if _, err := e.AddDocument("phrase_test", doc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	phrase := func(query string, slop int) *model.Query {
+		return &model.Query{MatchPhrase: &model.MatchPhraseQuery{Field: "title", Query: query, Slop: slop}}
+	}
+	tests := []struct {
+		name string
+		q    *model.Query
+		want uint64
+	}{
+		{"phrase", phrase("南京市长江大桥", 0), 1},
+		{"phrase across whitespace", phrase("NFC手机", 0), 1},
+		{"phrase not adjacent", phrase("小米手机", 0), 0},
+		{"slop", phrase("小米手机", 1), 2},
+		{"slop too small", phrase("长江大桥南京市", 0), 0},
+		{"slop with gap", phrase("长江大桥南京市", 1), 1},
+		{"slop keeps order", phrase("手机小米", 2), 0},
+		{"slop in bool", &model.Query{Bool: &model.BoolQuery{
+			Must:    []model.Query{*phrase("小米手机", 1)},
+			MustNot: []model.Query{{Term: &model.TermQuery{Field: "title", Value: "的"}}},
+		}}, 1},
+	}
+	for _, tt := range tests {
+		if n := countMatches(t, e, "phrase_test", tt.q); n != tt.want {
+			t.Errorf("%s: total = %d, want %d", tt.name, n, tt.want)
+		}
+	}
+}
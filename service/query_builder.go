@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-search/model"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// 根据查询条件构建bleve查询
+func BuildSearchQuery(q model.SearchQuery) (query.Query, error) {
+	switch q.Type {
+	case 1:
+		return bleve.NewQueryStringQuery(q.Query), nil
+	case 2:
+		rangeQuery := bleve.NewNumericRangeQuery(&q.Start, &q.End)
+		rangeQuery.SetField(q.Field)
+		return rangeQuery, nil
+	case 3:
+		return BuildQuery(q.DSL)
+	default:
+		return nil, fmt.Errorf("不支持的查询类型: %d", q.Type)
+	}
+}
+
+// 将结构化查询DSL转换为bleve查询
+func BuildQuery(q *model.Query) (query.Query, error) {
+	if q == nil {
+		return nil, fmt.Errorf("查询条件不能为空")
+	}
+
+	var (
+		result query.Query
+		boost  *float64
+		count  int
+	)
+
+	if q.Bool != nil {
+		count++
+		boolQuery, err := buildBoolQuery(q.Bool)
+		if err != nil {
+			return nil, err
+		}
+		result, boost = boolQuery, q.Bool.Boost
+	}
+	if q.Match != nil {
+		count++
+		if q.Match.Query == "" {
+			return nil, fmt.Errorf("match 查询内容不能为空")
+		}
+		matchQuery := bleve.NewMatchQuery(q.Match.Query)
+		matchQuery.SetField(q.Match.Field)
+		matchQuery.Analyzer = q.Match.Analyzer
+		matchQuery.SetFuzziness(q.Match.Fuzziness)
+		switch strings.ToLower(q.Match.Operator) {
+		case "", "or":
+			matchQuery.SetOperator(query.MatchQueryOperatorOr)
+		case "and":
+			matchQuery.SetOperator(query.MatchQueryOperatorAnd)
+		default:
+			return nil, fmt.Errorf("不支持的 match 操作符: %s", q.Match.Operator)
+		}
+		result, boost = matchQuery, q.Match.Boost
+	}
+	if q.MatchPhrase != nil {
+		count++
+		if q.MatchPhrase.Query == "" {
+			return nil, fmt.Errorf("match_phrase 查询内容不能为空")
+		}
+		switch {
+		case q.MatchPhrase.Slop < 0:
+			return nil, fmt.Errorf("match_phrase 的 slop 不能为负数")
+		case q.MatchPhrase.Slop > 0:
+			phraseQuery := newSlopPhraseQuery(q.MatchPhrase.Query, q.MatchPhrase.Slop)
+			phraseQuery.SetField(q.MatchPhrase.Field)
+			phraseQuery.analyzer = q.MatchPhrase.Analyzer
+			result = phraseQuery
+		default:
+			phraseQuery := bleve.NewMatchPhraseQuery(q.MatchPhrase.Query)
+			phraseQuery.SetField(q.MatchPhrase.Field)
+			phraseQuery.Analyzer = q.MatchPhrase.Analyzer
+			result = phraseQuery
+		}
+		boost = q.MatchPhrase.Boost
+	}
+	if q.Term != nil {
+		count++
+		termQuery := bleve.NewTermQuery(q.Term.Value)
+		termQuery.SetField(q.Term.Field)
+		result, boost = termQuery, q.Term.Boost
+	}
+	if q.Terms != nil {
+		count++
+		if len(q.Terms.Values) == 0 {
+			return nil, fmt.Errorf("terms 查询至少需要一个词条")
+		}
+		termQueries := make([]query.Query, 0, len(q.Terms.Values))
+		for _, value := range q.Terms.Values {
+			termQuery := bleve.NewTermQuery(value)
+			termQuery.SetField(q.Terms.Field)
+			termQueries = append(termQueries, termQuery)
+		}
+		result, boost = bleve.NewDisjunctionQuery(termQueries...), q.Terms.Boost
+	}
+	if q.Prefix != nil {
+		count++
+		prefixQuery := bleve.NewPrefixQuery(q.Prefix.Value)
+		prefixQuery.SetField(q.Prefix.Field)
+		result, boost = prefixQuery, q.Prefix.Boost
+	}
+	if q.Wildcard != nil {
+		count++
+		wildcardQuery := bleve.NewWildcardQuery(q.Wildcard.Value)
+		wildcardQuery.SetField(q.Wildcard.Field)
+		result, boost = wildcardQuery, q.Wildcard.Boost
+	}
+	if q.Regexp != nil {
+		count++
+		regexpQuery := bleve.NewRegexpQuery(q.Regexp.Value)
+		regexpQuery.SetField(q.Regexp.Field)
+		result, boost = regexpQuery, q.Regexp.Boost
+	}
+	if q.Fuzzy != nil {
+		count++
+		fuzzyQuery := bleve.NewFuzzyQuery(q.Fuzzy.Value)
+		fuzzyQuery.SetField(q.Fuzzy.Field)
+		if q.Fuzzy.Fuzziness > 0 {
+			fuzzyQuery.SetFuzziness(q.Fuzzy.Fuzziness)
+		}
+		fuzzyQuery.SetPrefix(q.Fuzzy.PrefixLength)
+		result, boost = fuzzyQuery, q.Fuzzy.Boost
+	}
+	if q.NumericRange != nil {
+		count++
+		r := q.NumericRange
+		min, minInclusive := rangeBound(r.GT, r.GTE)
+		max, maxInclusive := rangeBound(r.LT, r.LTE)
+		if min == nil && max == nil {
+			return nil, fmt.Errorf("numeric_range 查询至少需要一个边界")
+		}
+		rangeQuery := bleve.NewNumericRangeInclusiveQuery(min, max, &minInclusive, &maxInclusive)
+		rangeQuery.SetField(r.Field)
+		result, boost = rangeQuery, r.Boost
+	}
+	if q.DateRange != nil {
+		count++
+		r := q.DateRange
+		start, startInclusive := dateRangeBound(r.GT, r.GTE)
+		end, endInclusive := dateRangeBound(r.LT, r.LTE)
+		if start == "" && end == "" {
+			return nil, fmt.Errorf("date_range 查询至少需要一个边界")
+		}
+		rangeQuery := bleve.NewDateRangeInclusiveStringQuery(start, end, &startInclusive, &endInclusive)
+		rangeQuery.SetField(r.Field)
+		if r.DateTimeParser != "" {
+			rangeQuery.SetDateTimeParser(r.DateTimeParser)
+		}
+		result, boost = rangeQuery, r.Boost
+	}
+	if q.MatchAll != nil {
+		count++
+		result, boost = bleve.NewMatchAllQuery(), q.MatchAll.Boost
+	}
+
+	switch {
+	case count == 0:
+		return nil, fmt.Errorf("查询条件不能为空")
+	case count > 1:
+		return nil, fmt.Errorf("每个查询节点只能包含一种查询子句")
+	}
+
+	if boost != nil {
+		if boostable, ok := result.(query.BoostableQuery); ok {
+			boostable.SetBoost(*boost)
+		}
+	}
+
+	return result, nil
+}
+
+// 构建布尔查询，filter子句作为不参与评分的must处理
+func buildBoolQuery(b *model.BoolQuery) (query.Query, error) {
+	must, err := buildQueries(b.Must)
+	if err != nil {
+		return nil, err
+	}
+	should, err := buildQueries(b.Should)
+	if err != nil {
+		return nil, err
+	}
+	mustNot, err := buildQueries(b.MustNot)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := buildQueries(b.Filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range filter {
+		must = append(must, &filterQuery{Query: f})
+	}
+
+	if len(must) == 0 && len(should) == 0 && len(mustNot) == 0 {
+		return nil, fmt.Errorf("bool 查询至少需要一个子句")
+	}
+
+	// 只有must_not时，匹配除排除项以外的所有文档
+	if len(must) == 0 && len(should) == 0 {
+		must = append(must, bleve.NewMatchAllQuery())
+	}
+
+	boolQuery := bleve.NewBooleanQuery()
+	boolQuery.AddMust(must...)
+	boolQuery.AddShould(should...)
+	boolQuery.AddMustNot(mustNot...)
+	if len(should) > 0 {
+		minShould := b.MinimumShould
+		// 没有must子句时，至少需要匹配一个should
+		if minShould == 0 && len(must) == 0 {
+			minShould = 1
+		}
+		boolQuery.SetMinShould(minShould)
+	}
+
+	return boolQuery, nil
+}
+
+// 只过滤文档、不参与评分的查询
+// 不能通过将 boost 设为 0 实现：只有 filter 子句时查询权重之和为 0，bleve 计算出的评分为 NaN
+type filterQuery struct {
+	query.Query
+}
+
+func (q *filterQuery) Searcher(ctx context.Context, i index.IndexReader, m mapping.IndexMapping, options search.SearcherOptions) (search.Searcher, error) {
+	searcher, err := q.Query.Searcher(ctx, i, m, options)
+	if err != nil {
+		return nil, err
+	}
+	return &filterSearcher{Searcher: searcher}, nil
+}
+
+// 匹配结果的评分固定为 0，权重为 0，忽略上层传入的查询归一化系数
+type filterSearcher struct {
+	search.Searcher
+}
+
+func (s *filterSearcher) Next(ctx *search.SearchContext) (*search.DocumentMatch, error) {
+	return clearScore(s.Searcher.Next(ctx))
+}
+
+func (s *filterSearcher) Advance(ctx *search.SearchContext, id index.IndexInternalID) (*search.DocumentMatch, error) {
+	return clearScore(s.Searcher.Advance(ctx, id))
+}
+
+func (s *filterSearcher) Weight() float64 {
+	return 0
+}
+
+func (s *filterSearcher) SetQueryNorm(float64) {}
+
+func clearScore(match *search.DocumentMatch, err error) (*search.DocumentMatch, error) {
+	if match != nil {
+		match.Score = 0
+		if match.Expl != nil {
+			match.Expl = &search.Explanation{Message: "filter"}
+		}
+	}
+	return match, err
+}
+
+func buildQueries(queries []model.Query) ([]query.Query, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	result := make([]query.Query, 0, len(queries))
+	for i := range queries {
+		q, err := BuildQuery(&queries[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, q)
+	}
+	return result, nil
+}
+
+// 解析范围边界，exclusive优先于inclusive
+func rangeBound(exclusive, inclusive *float64) (*float64, bool) {
+	if exclusive != nil {
+		return exclusive, false
+	}
+	if inclusive != nil {
+		return inclusive, true
+	}
+	return nil, false
+}
+
+func dateRangeBound(exclusive, inclusive string) (string, bool) {
+	if exclusive != "" {
+		return exclusive, false
+	}
+	return inclusive, inclusive != ""
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"encoding/json"
+	"go-search/model"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+func TestQuerySearch(t *testing.T) {
+	e := newTestEngine(t)
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping.AddFieldMappingsAt("category", bleve.NewKeywordFieldMapping())
+	index := newTestIndex(t, e, "query_test", indexMapping)
+
+	docs := map[string]map[string]interface{}{
+		"1": {"name": "apple iphone 13", "category": "phone", "price": 5999},
+		"2": {"name": "apple ipad air", "category": "tablet", "price": 4399},
+		"3": {"name": "huawei mate 60", "category": "phone", "price": 6999},
+	}
+	for id, fields := range docs {
+		if err := index.Index(id, fields); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  uint64
+	}{
+		{"match", `{"match": {"field": "name", "query": "apple"}}`, 2},
+		{"match and", `{"match": {"field": "name", "query": "apple mate", "operator": "and"}}`, 0},
+		{"match_phrase", `{"match_phrase": {"field": "name", "query": "ipad air"}}`, 1},
+		{"term", `{"term": {"field": "category", "value": "phone"}}`, 2},
+		{"terms", `{"terms": {"field": "category", "values": ["phone", "tablet"]}}`, 3},
+		{"prefix", `{"prefix": {"field": "name", "value": "hua"}}`, 1},
+		{"wildcard", `{"wildcard": {"field": "name", "value": "i*d"}}`, 1},
+		{"regexp", `{"regexp": {"field": "name", "value": "iph.*"}}`, 1},
+		{"fuzzy", `{"fuzzy": {"field": "name", "value": "appel", "fuzziness": 2}}`, 2},
+		{"numeric_range", `{"numeric_range": {"field": "price", "gte": 5999, "lt": 6999}}`, 1},
+		{"match_all", `{"match_all": {}}`, 3},
+		{"bool", `{"bool": {
+			"must": [{"match": {"field": "name", "query": "apple"}}],
+			"must_not": [{"term": {"field": "category", "value": "tablet"}}],
+			"filter": [{"numeric_range": {"field": "price", "gte": 1000}}]
+		}}`, 1},
+		{"bool should", `{"bool": {"should": [
+			{"term": {"field": "category", "value": "tablet"}},
+			{"match": {"field": "name", "query": "huawei"}}
+		]}}`, 2},
+		{"bool must_not only", `{"bool": {"must_not": [{"term": {"field": "category", "value": "phone"}}]}}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q model.Query
+			if err := json.Unmarshal([]byte(tt.query), &q); err != nil {
+				t.Fatal(err)
+			}
+			result, err := e.QuerySearch("query_test", &q, model.SearchOptions{Page: 1, Size: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Total != tt.want {
+				t.Errorf("total = %d, want %d", result.Total, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildQueryInvalid(t *testing.T) {
+	tests := []string{
+		`{}`,
+		`{"match": {"field": "name", "query": "a"}, "term": {"field": "name", "value": "a"}}`,
+		`{"numeric_range": {"field": "price"}}`,
+		`{"bool": {}}`,
+		`{"match": {"field": "name", "query": "a", "operator": "xor"}}`,
+		`{"match_phrase": {"field": "name", "query": "a b", "slop": -1}}`,
+	}
+	for _, tt := range tests {
+		var q model.Query
+		if err := json.Unmarshal([]byte(tt), &q); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := BuildQuery(&q); err == nil {
+			t.Errorf("BuildQuery(%s) expected error", tt)
+		}
+	}
+}
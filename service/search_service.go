@@ -2,107 +2,120 @@ package service
 
 import (
 	"fmt"
-	"go-search/analysis/jieba"
 	"go-search/model"
-	"log"
+	"log/slog"
 	"math"
 	"os"
-	"regexp"
 	"sort"
-	"sync"
+	"strings"
 
 	"github.com/blevesearch/bleve/v2"
-	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
 )
 
-var (
-	indexes        = make(map[string]bleve.Index)
-	mu             sync.RWMutex
-	indexNameRegex = regexp.MustCompile(`^[a-zA-Z_.]+$`)
-)
-
-// 验证索引名称是否合法
-func IsValidIndexName(name string) bool {
-	// 验证索引名称只能包含字母、下划线和点
-	return indexNameRegex.MatchString(name)
-}
-
 // 初始化索引 - 支持字段分词器配置
-func InitIndex(indexName string, fields map[string]string) error {
+func (e *Engine) InitIndex(indexName string, fields map[string]model.FieldMapping, opts model.IndexOptions) error {
 
 	// 验证索引名称是否合法
 	if !IsValidIndexName(indexName) {
-		return fmt.Errorf("索引名称不合法")
+		return invalidRequest("索引名称不合法")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.indexes[indexName]; exists {
+		return indexConflict(indexName, "已存在")
+	}
+	if _, closed := e.closedIndexes[indexName]; closed {
+		return indexConflict(indexName, "已关闭")
+	}
+
+	// 以下为索引配置的校验，失败时属于请求不合法
+	storage, err := e.resolveStorage(opts.Storage)
+	if err != nil {
+		return asInvalid(err)
+	}
+	dynamic, err := e.newDynamicMapping(opts)
+	if err != nil {
+		return asInvalid(err)
+	}
+	keywords, fields, err := checkKeywordExtractions(opts.Keywords, fields)
+	if err != nil {
+		return asInvalid(err)
+	}
+	indexMapping, err := e.buildIndexMapping(fields, opts.Analysis, dynamic.Mode == model.DynamicTrue)
+	if err != nil {
+		return asInvalid(err)
 	}
 
-	mu.Lock()
-	defer mu.Unlock()
+	// 内存索引直接创建，不读写数据目录
+	if storage == model.StorageMemory {
+		index, err := bleve.NewMemOnly(indexMapping)
+		if err != nil {
+			return fmt.Errorf("创建索引失败: %v", err)
+		}
+		if err := e.registerNewIndex(indexName, index, dynamic, keywords); err != nil {
+			return err
+		}
+		e.memoryIndexes[indexName] = struct{}{}
+		return nil
+	}
 
-	if _, exists := indexes[indexName]; exists {
-		return fmt.Errorf("索引 %s 已存在", indexName)
+	// 已关闭的索引保持关闭，需通过 OpenIndex 重新打开
+	if e.closedOnDisk(indexName) {
+		e.closedIndexes[indexName] = struct{}{}
+		return nil
 	}
 
 	// 尝试打开已存在的索引
-	index, err := bleve.Open("./data/" + indexName)
+	index, err := bleve.Open(e.indexPath(indexName))
 	if err == nil {
-		indexes[indexName] = index
-		return nil
+		return e.registerIndex(indexName, index)
 	}
 
 	// 如果索引不存在，则创建新索引
 	if err == bleve.ErrorIndexPathDoesNotExist {
-		indexMapping := bleve.NewIndexMapping()
-
-		// 配置字段分词器
-		for fieldName, analyzer := range fields {
-			var fieldMapping *mapping.FieldMapping
-
-			// 根据配置设置分析器
-			switch analyzer {
-			case "jieba":
-				fieldMapping = bleve.NewTextFieldMapping()
-				fieldMapping.Analyzer = jieba.AnalyzerName
-			case "keyword":
-				fieldMapping = bleve.NewKeywordFieldMapping()
-			case "number":
-				fieldMapping = bleve.NewNumericFieldMapping()
-				log.Printf("number field: %s", fieldName)
-			default:
-				fieldMapping = bleve.NewTextFieldMapping()
-			}
-
-			indexMapping.DefaultMapping.AddFieldMappingsAt(fieldName, fieldMapping)
-		}
-
-		index, err = bleve.New("./data/"+indexName, indexMapping)
+		index, err = bleve.New(e.indexPath(indexName), indexMapping)
 		if err != nil {
 			return fmt.Errorf("创建索引失败: %v", err)
 		}
-		indexes[indexName] = index
-		return nil
+		return e.registerNewIndex(indexName, index, dynamic, keywords)
 	}
 
 	return fmt.Errorf("打开索引失败: %v", err)
 }
 
-// 加载所有已存在的索引
-func LoadAllIndexes() error {
+// 加载所有已存在的索引，内存模式下没有需要加载的索引
+func (e *Engine) LoadAllIndexes() error {
+	if e.inMemory {
+		return nil
+	}
 
 	// 读取当前目录下的所有项目
-	entries, err := os.ReadDir("./data")
+	entries, err := os.ReadDir(e.dataDir)
 	if err != nil {
 		return fmt.Errorf("读取目录失败: %v", err)
 	}
 
 	for _, entry := range entries {
 		if entry.IsDir() {
+			// 跳过已按配置初始化的索引
+			e.mu.RLock()
+			_, loaded := e.indexes[entry.Name()]
+			_, closed := e.closedIndexes[entry.Name()]
+			e.mu.RUnlock()
+			if loaded || closed {
+				continue
+			}
 			// 尝试打开目录作为索引
-			err = InitIndex(entry.Name(), nil)
-			if err == nil {
-				log.Printf("成功加载索引: %s", entry.Name())
+			err = e.InitIndex(entry.Name(), nil, model.IndexOptions{Storage: model.StorageDisk})
+			if err == nil && e.closedOnDisk(entry.Name()) {
+				slog.Info("索引已关闭，未加载", "index", entry.Name())
+			} else if err == nil {
+				slog.Info("成功加载索引", "index", entry.Name())
 			} else {
-				// 仅记录非不存在错误的警告
-				log.Printf("警告: 无法打开目录 %s 作为索引: %v", entry.Name(), err)
+				slog.Warn("无法打开目录作为索引", "dir", entry.Name(), "error", err)
 			}
 		}
 	}
@@ -111,109 +124,140 @@ func LoadAllIndexes() error {
 }
 
 // 添加文档到指定索引
-func AddDocument(indexName string, doc model.Document) error {
-	mu.RLock()
-	defer mu.RUnlock()
+// ifVersion 不为空时，仅在文档当前版本与之一致时写入(文档不存在时版本为0)
+func (e *Engine) AddDocument(indexName string, doc model.Document, ifVersion *uint64) (*model.WriteResult, error) {
+	if err := e.prepareFields(indexName, doc.Fields); err != nil {
+		return nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	index, exists := indexes[indexName]
+	index, exists := e.indexes[indexName]
 	if !exists {
-		return fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
 	}
 
-	return index.Index(doc.ID, doc.Fields)
+	return writeDocument(index, e.writers[indexName], indexName, doc, model.UpdateModeReplace, ifVersion)
 }
 
 // 更新文档
-func UpdateDocument(indexName string, doc model.Document) error {
-	mu.RLock()
-	defer mu.RUnlock()
+// replace: 整体覆盖; merge: 将字段深度合并到已存在的文档; upsert: 文档存在时合并，否则新建
+func (e *Engine) UpdateDocument(indexName string, doc model.Document, mode string, ifVersion *uint64) (*model.WriteResult, error) {
+	if err := e.prepareFields(indexName, doc.Fields); err != nil {
+		return nil, err
+	}
 
-	index, exists := indexes[indexName]
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	index, exists := e.indexes[indexName]
 	if !exists {
-		return fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
 	}
 
-	// 使用bleve的Index方法实现更新（已存在的ID会被覆盖）
-	return index.Index(doc.ID, doc.Fields)
+	return writeDocument(index, e.writers[indexName], indexName, doc, mode, ifVersion)
 }
 
 // 删除文档
-func DeleteDocument(indexName string, docID string) error {
-	mu.RLock()
-	defer mu.RUnlock()
+func (e *Engine) DeleteDocument(indexName string, docID string, ifVersion *uint64) (*model.WriteResult, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	index, exists := indexes[indexName]
+	index, exists := e.indexes[indexName]
 	if !exists {
-		return fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
+	}
+
+	return deleteDocument(index, e.writers[indexName], indexName, docID, ifVersion)
+}
+
+// 使用查询字符串搜索文档，等同于 type 为 1 的 SearchByQuery
+func (e *Engine) Search(indexName string, query string, opts model.SearchOptions) (*bleve.SearchResult, error) {
+	return e.SearchByQuery(indexName, model.SearchQuery{Type: 1, Query: query}, opts)
+}
+
+// 使用数字范围查询文档，等同于 type 为 2 的 SearchByQuery
+func (e *Engine) RangeSearch(indexName string, field string, start, end float64, opts model.SearchOptions) (*bleve.SearchResult, error) {
+	return e.SearchByQuery(indexName, model.SearchQuery{Type: 2, Field: field, Start: start, End: end}, opts)
+}
+
+// 使用结构化查询DSL搜索文档，等同于 type 为 3 的 SearchByQuery
+func (e *Engine) QuerySearch(indexName string, q *model.Query, opts model.SearchOptions) (*bleve.SearchResult, error) {
+	return e.SearchByQuery(indexName, model.SearchQuery{Type: 3, DSL: q}, opts)
+}
+
+// 按查询条件搜索文档，与按查询删除/更新使用相同的查询构建方式
+func (e *Engine) SearchByQuery(indexName string, q model.SearchQuery, opts model.SearchOptions) (*bleve.SearchResult, error) {
+	searchQuery, err := BuildSearchQuery(q)
+	if err != nil {
+		return nil, asInvalid(err)
 	}
 
-	return index.Delete(docID)
+	return e.searchWithQuery(indexName, searchQuery, opts)
 }
 
-// 搜索文档 (增加分页参数)
-func Search(indexName string, query string, page, size int, sortBy string) (*bleve.SearchResult, error) {
-	mu.RLock()
-	defer mu.RUnlock()
+// 执行查询并返回分页结果
+func (e *Engine) searchWithQuery(indexName string, searchQuery query.Query, opts model.SearchOptions) (*bleve.SearchResult, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	index, exists := indexes[indexName]
+	index, exists := e.indexes[indexName]
 	if !exists {
-		return nil, fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
 	}
 
-	searchQuery := bleve.NewQueryStringQuery(query) // NewMatchQuery
 	searchRequest := bleve.NewSearchRequest(searchQuery)
-	if sortBy != "" {
+	if opts.SortBy != "" {
 		// 设置排序
-		searchRequest.SortBy([]string{sortBy})
+		searchRequest.SortBy([]string{opts.SortBy})
 	}
 
 	// 返回所有字段
 	searchRequest.Fields = []string{"*"}
 
 	// 设置分页
-	from := (page - 1) * size
-	searchRequest.From = from
-	searchRequest.Size = size
-
-	return index.Search(searchRequest)
-}
+	searchRequest.From = (opts.Page - 1) * opts.Size
+	searchRequest.Size = opts.Size
 
-// 使用范围查询文档
-func RangeSearch(indexName string, field string, start, end float64, page, size int, sortBy string) (*bleve.SearchResult, error) {
-	mu.RLock()
-	defer mu.RUnlock()
-
-	index, exists := indexes[indexName]
-	if !exists {
-		return nil, fmt.Errorf("索引 %s 不存在", indexName)
+	// 设置高亮
+	var highlightTags *strings.Replacer
+	if opts.Highlight != nil {
+		highlight, tags, err := buildHighlight(opts.Highlight)
+		if err != nil {
+			return nil, asInvalid(err)
+		}
+		searchRequest.Highlight = highlight
+		highlightTags = tags
 	}
-	// max := 50.0
-	// maxInclusive := true
-	// q := NewNumericRangeInclusiveQuery(nil, &max, nil, &maxInclusive)
-	// q.SetField("price")
-	rangeQuery := bleve.NewNumericRangeQuery(&start, &end)
-	rangeQuery.SetField(field)
 
-	searchRequest := bleve.NewSearchRequest(rangeQuery)
-	searchRequest.Fields = []string{"*"}
-	searchRequest.From = (page - 1) * size
-	searchRequest.Size = size
-	if sortBy != "" {
-		// 设置排序
-		searchRequest.SortBy([]string{sortBy})
+	// 设置分面统计，基于全部命中结果计算
+	if len(opts.Facets) > 0 {
+		facets, err := buildFacets(opts.Facets)
+		if err != nil {
+			return nil, asInvalid(err)
+		}
+		searchRequest.Facets = facets
 	}
 
-	return index.Search(searchRequest)
+	result, err := index.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+	if highlightTags != nil {
+		applyHighlightTags(result.Hits, highlightTags)
+	}
+	return result, nil
 }
 
 // 获取索引统计信息
-func GetIndexStatistics(indexName string) (*model.IndexStatistics, error) {
-	mu.RLock()
-	defer mu.RUnlock()
+func (e *Engine) GetIndexStatistics(indexName string) (*model.IndexStatistics, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	index, exists := indexes[indexName]
+	index, exists := e.indexes[indexName]
 	if !exists {
-		return nil, fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
 	}
 
 	stats := &model.IndexStatistics{
@@ -258,13 +302,13 @@ func GetIndexStatistics(indexName string) (*model.IndexStatistics, error) {
 }
 
 // 返回按频率排序的词条列表（降序）
-func GetTermFrequencyRanking(indexName string) ([]model.TermFrequency, error) {
-	mu.RLock()
-	defer mu.RUnlock()
+func (e *Engine) GetTermFrequencyRanking(indexName string) ([]model.TermFrequency, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	idx, exists := indexes[indexName]
+	idx, exists := e.indexes[indexName]
 	if !exists {
-		return nil, fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
 	}
 
 	// 获取所有字段
@@ -283,8 +327,9 @@ func GetTermFrequencyRanking(indexName string) ([]model.TermFrequency, error) {
 			continue
 		}
 
+		// 只统计文本字段，其他类型的词条为编码后的值
 		fieldMapping := mapping.FieldMappingForPath(field)
-		if fieldMapping.Type == "number" {
+		if fieldMapping.Type != "" && fieldMapping.Type != "text" {
 			continue
 		}
 
@@ -323,13 +368,13 @@ func GetTermFrequencyRanking(indexName string) ([]model.TermFrequency, error) {
 }
 
 // 统计数字字段的范围分布
-func GetNumberFieldRangeDistribution(indexName, fieldName string, ranges [][2]float64) (*model.RangeDistribution, error) {
-	mu.RLock()
-	defer mu.RUnlock()
+func (e *Engine) GetNumberFieldRangeDistribution(indexName, fieldName string, ranges [][2]float64) (*model.RangeDistribution, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 
-	index, exists := indexes[indexName]
+	index, exists := e.indexes[indexName]
 	if !exists {
-		return nil, fmt.Errorf("索引 %s 不存在", indexName)
+		return nil, indexNotFound(indexName)
 	}
 
 	// 验证字段是否为数字类型
@@ -343,7 +388,7 @@ func GetNumberFieldRangeDistribution(indexName, fieldName string, ranges [][2]fl
 	query := bleve.NewMatchAllQuery()
 	searchRequest := bleve.NewSearchRequest(query)
 	searchRequest.Fields = []string{fieldName}
-	searchRequest.Size = 10000 // 适当调整批量大小
+	searchRequest.Size = e.statsScanSize
 
 	// 执行查询
 	results, err := index.Search(searchRequest)
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+)
+
+// 创建内存模式的引擎，测试结束后关闭所有索引
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+
+	e := NewEngine(WithInMemory())
+	t.Cleanup(func() { e.CloseAll() })
+	return e
+}
+
+// 使用指定映射创建内存索引并注册到引擎
+func newTestIndex(t *testing.T, e *Engine, indexName string, indexMapping mapping.IndexMapping) bleve.Index {
+	t.Helper()
+
+	index, err := bleve.NewMemOnly(indexMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.mu.Lock()
+	err = e.registerIndex(indexName, index)
+	e.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+// 搜索结果中的文档ID，按结果顺序
+func hitIDs(hits search.DocumentMatchCollection) []string {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}